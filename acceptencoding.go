@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	unacceptableEncodingMsg = "unacceptable_encoding"
+	negotiatedEncodingKey   = "negotiated_encoding"
+)
+
+type AcceptEncodingOptions struct {
+	// Encodings the route can produce, in order of preference.
+	Encodings []string
+	// Identity serves the response unencoded with a Warning header instead
+	// of rejecting clients that accept none of Encodings.
+	Identity bool
+}
+
+// RequireAcceptEncoding aborts with 406 when the client's Accept-Encoding
+// admits none of opts.Encodings. Attach it to the routes or groups it applies
+// to, each with its own options. Handlers read the pick with
+// NegotiatedEncoding.
+func RequireAcceptEncoding(logger *zap.Logger, opts AcceptEncodingOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accepted := parseAcceptEncoding(c.GetHeader("Accept-Encoding"))
+		for _, enc := range opts.Encodings {
+			if acceptsEncoding(accepted, enc) {
+				c.Set(negotiatedEncodingKey, enc)
+				c.Next()
+				return
+			}
+		}
+
+		logger.Warn(unacceptableEncodingMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("path_uri", routePath(c)),
+			zap.String("accept_encoding", c.GetHeader("Accept-Encoding")),
+			zap.Strings("encodings", opts.Encodings),
+		)
+		if !opts.Identity {
+			abortWithError(c, http.StatusNotAcceptable, "none of the supported encodings are acceptable: "+strings.Join(opts.Encodings, ", "))
+			return
+		}
+		c.Header("Warning", `214 - "response served without the required encoding"`)
+		c.Set(negotiatedEncodingKey, "identity")
+		c.Next()
+	}
+}
+
+// NegotiatedEncoding returns the encoding RequireAcceptEncoding settled on,
+// "identity" if it fell back, or "" outside it.
+func NegotiatedEncoding(c *gin.Context) string {
+	return c.GetString(negotiatedEncodingKey)
+}
+
+// parseAcceptEncoding maps each coding, lowercased, to its q-value.
+func parseAcceptEncoding(header string) map[string]float64 {
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		accepted[coding] = q
+	}
+	return accepted
+}
+
+// acceptsEncoding follows RFC 9110 12.5.3: an explicit q beats *, and
+// identity is acceptable unless excluded.
+func acceptsEncoding(accepted map[string]float64, enc string) bool {
+	enc = strings.ToLower(enc)
+	if q, ok := accepted[enc]; ok {
+		return q > 0
+	}
+	if q, ok := accepted["*"]; ok {
+		return q > 0
+	}
+	return enc == "identity"
+}
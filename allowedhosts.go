@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const hostRejectedMsg = "host_rejected"
+
+// AllowedHosts rejects with 400 requests whose Host is not in hosts. A
+// "*.example.com" entry matches any subdomain of example.com but not
+// example.com itself. Ports are ignored.
+func AllowedHosts(logger *zap.Logger, hosts ...string) gin.HandlerFunc {
+	exact := map[string]bool{}
+	var suffixes []string
+	for _, h := range hosts {
+		h = strings.ToLower(h)
+		if strings.HasPrefix(h, "*.") {
+			suffixes = append(suffixes, h[1:])
+		} else {
+			exact[h] = true
+		}
+	}
+	return func(c *gin.Context) {
+		host := strings.ToLower(stripPort(c.Request.Host))
+		if !hostAllowed(host, exact, suffixes) {
+			logger.Warn(hostRejectedMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("host", c.Request.Host),
+				zap.String("path_uri", routePath(c)),
+			)
+			abortWithError(c, http.StatusBadRequest, "invalid host")
+			return
+		}
+		c.Next()
+	}
+}
+
+func hostAllowed(host string, exact map[string]bool, suffixes []string) bool {
+	if exact[host] {
+		return true
+	}
+	for _, s := range suffixes {
+		if len(host) > len(s) && strings.HasSuffix(host, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func stripPort(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return strings.Trim(hostport, "[]")
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAllowedHosts(t *testing.T) {
+	tests := []struct {
+		name          string
+		host          string
+		forwardedHost string
+		want          int
+	}{
+		{"exact", "api.test", "", http.StatusOK},
+		{"port ignored", "api.test:8443", "", http.StatusOK},
+		{"case folded", "API.Test", "", http.StatusOK},
+		{"ipv6", "[::1]:8080", "", http.StatusOK},
+		{"subdomain", "a.example.com", "", http.StatusOK},
+		{"nested subdomain", "a.b.example.com", "", http.StatusOK},
+		{"wildcard apex", "example.com", "", http.StatusBadRequest},
+		{"suffix lookalike", "evilexample.com", "", http.StatusBadRequest},
+		{"spoofed host", "evil.test", "", http.StatusBadRequest},
+		{"allowed host as prefix", "api.test.evil.test", "", http.StatusBadRequest},
+		{"empty host", "", "", http.StatusBadRequest},
+		// X-Forwarded-Host is client controlled and never consulted.
+		{"spoofed forwarded host", "evil.test", "api.test", http.StatusBadRequest},
+		{"forwarded host ignored", "api.test", "evil.test", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.WarnLevel)
+			reached := false
+			r := gin.New()
+			r.Use(AllowedHosts(logger, "api.test", "::1", "*.example.com"))
+			r.GET("/x", func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			req.Host = tt.host
+			if tt.forwardedHost != "" {
+				req.Header.Set("X-Forwarded-Host", tt.forwardedHost)
+			}
+			w := serve(r, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if reached != (tt.want == http.StatusOK) {
+				t.Errorf("handler reached = %v", reached)
+			}
+			if tt.want == http.StatusOK {
+				if logs.Len() != 0 {
+					t.Errorf("logs = %v, want none", summaries(logs))
+				}
+				return
+			}
+			entries := logs.FilterMessage(hostRejectedMsg).All()
+			if len(entries) != 1 {
+				t.Fatalf("logs = %v, want one %s", summaries(logs), hostRejectedMsg)
+			}
+			if got := entries[0].ContextMap()["host"]; got != tt.host {
+				t.Errorf("host = %v, want %q", got, tt.host)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const deprecatedVersionMsg = "deprecated_api_version"
+
+type VersionPolicy struct {
+	// Gone rejects the version with 410 and Message.
+	Gone    bool
+	Message string
+	// Deprecated serves the request with a Deprecation header, plus Sunset
+	// when set.
+	Deprecated bool
+	Sunset     time.Time
+}
+
+type VersionOptions struct {
+	// Version extracts the request's version, see VersionFromPath,
+	// VersionFromHeader and VersionFromQuery. Defaults to VersionFromPath.
+	Version  func(c *gin.Context) string
+	Policies map[string]VersionPolicy
+}
+
+// APIVersion applies the policy of the request's API version and logs every
+// hit on a gone or deprecated version.
+func APIVersion(logger *zap.Logger, opts VersionOptions) gin.HandlerFunc {
+	if opts.Version == nil {
+		opts.Version = VersionFromPath
+	}
+	return func(c *gin.Context) {
+		version := opts.Version(c)
+		policy, ok := opts.Policies[version]
+		if !ok || (!policy.Gone && !policy.Deprecated) {
+			c.Next()
+			return
+		}
+
+		logger.Info(deprecatedVersionMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("method", c.Request.Method),
+			zap.String("path_uri", routePath(c)),
+			zap.String("version", version),
+			zap.Bool("gone", policy.Gone),
+		)
+		if policy.Gone {
+			message := policy.Message
+			if message == "" {
+				message = "API version " + version + " is no longer supported"
+			}
+			abortWithError(c, http.StatusGone, message)
+			return
+		}
+		c.Header("Deprecation", "true")
+		if !policy.Sunset.IsZero() {
+			c.Header("Sunset", policy.Sunset.UTC().Format(http.TimeFormat))
+		}
+		c.Next()
+	}
+}
+
+// VersionFromPath takes the first path segment, e.g. "v1" for /v1/users.
+func VersionFromPath(c *gin.Context) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(c.Request.URL.Path, "/"), "/")
+	return segment
+}
+
+func VersionFromHeader(name string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		return c.GetHeader(name)
+	}
+}
+
+func VersionFromQuery(name string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		return c.Query(name)
+	}
+}
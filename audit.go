@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const auditMsg = "audit"
+
+type AuditOptions struct {
+	// Principal extracts the authenticated caller, e.g. from c.Get.
+	Principal func(c *gin.Context) string
+	// Methods to audit; defaults to POST, PUT, PATCH and DELETE.
+	Methods []string
+}
+
+// Audit writes one entry per audited request to auditLogger, separate from the
+// general request logs so it can be routed to a write-once store.
+func Audit(auditLogger *zap.Logger, opts AuditOptions) gin.HandlerFunc {
+	methods := opts.Methods
+	if len(methods) == 0 {
+		methods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	audited := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		audited[m] = true
+	}
+
+	return func(c *gin.Context) {
+		if !audited[c.Request.Method] {
+			c.Next()
+			return
+		}
+
+		ts := time.Now()
+		defer func() {
+			r := recover()
+			status := finalStatus(c, r)
+			principal := ""
+			if opts.Principal != nil {
+				principal = opts.Principal(c)
+			}
+			auditLogger.Info(auditMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("method", c.Request.Method),
+				zap.String("path_uri", routePath(c)),
+				zap.String("principal", principal),
+				zap.Int("status", status),
+				zap.Time("timestamp", ts),
+			)
+			if r != nil {
+				panic(r)
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	baggageHeader     = "baggage"
+	maxBaggageMembers = 64
+	maxBaggageBytes   = 8192
+)
+
+type baggageKey struct{}
+
+// Baggage parses the W3C baggage header into the request context, from where
+// PropagateRequestID forwards it. Headers over 8192 bytes are ignored and only
+// the first 64 members are kept.
+func Baggage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if b := parseBaggage(c.Request.Header.Values(baggageHeader)); len(b) > 0 {
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), baggageKey{}, b))
+		}
+		c.Next()
+	}
+}
+
+// BaggageFromContext returns the baggage set by Baggage. It accepts either the
+// request context or the *gin.Context.
+func BaggageFromContext(ctx context.Context) map[string]string {
+	ctx = requestContext(ctx)
+	b, _ := ctx.Value(baggageKey{}).(map[string]string)
+	return b
+}
+
+// WithBaggageFields logs the named baggage keys as baggage.<key> fields on the
+// api summary.
+func WithBaggageFields(keys ...string) Option {
+	return func(o *options) {
+		o.baggageFields = append(o.baggageFields, keys...)
+	}
+}
+
+func (o *options) baggageLogFields(c *gin.Context) []zap.Field {
+	b := BaggageFromContext(c)
+	if len(b) == 0 {
+		return nil
+	}
+	var zf []zap.Field
+	for _, k := range o.baggageFields {
+		if v, ok := b[k]; ok {
+			zf = append(zf, zap.String("baggage."+k, v))
+		}
+	}
+	return zf
+}
+
+func parseBaggage(headers []string) map[string]string {
+	raw := strings.Join(headers, ",")
+	if raw == "" || len(raw) > maxBaggageBytes {
+		return nil
+	}
+	b := map[string]string{}
+	for _, member := range strings.Split(raw, ",") {
+		if len(b) >= maxBaggageMembers {
+			break
+		}
+		member, _, _ = strings.Cut(member, ";")
+		k, v, ok := strings.Cut(member, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			continue
+		}
+		v, err := url.PathUnescape(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+		b[k] = v
+	}
+	return b
+}
+
+// formatBaggage is the baggage header for b, with members sorted by key.
+func formatBaggage(b map[string]string) string {
+	keys := make([]string, 0, len(b))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	members := make([]string, len(keys))
+	for i, k := range keys {
+		members[i] = k + "=" + url.PathEscape(b[k])
+	}
+	return strings.Join(members, ",")
+}
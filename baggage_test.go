@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseBaggage(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    map[string]string
+	}{
+		{"none", nil, nil},
+		{"empty", []string{""}, nil},
+		{"single", []string{"user=alice"}, map[string]string{"user": "alice"}},
+		{"several", []string{"a=1, b=2"}, map[string]string{"a": "1", "b": "2"}},
+		{"several headers", []string{"a=1", "b=2"}, map[string]string{"a": "1", "b": "2"}},
+		{"properties dropped", []string{"a=1;prop=x;flag"}, map[string]string{"a": "1"}},
+		{"percent-encoded", []string{"city=S%C3%A3o%20Paulo"}, map[string]string{"city": "São Paulo"}},
+		{"bad escape skipped", []string{"a=%zz,b=2"}, map[string]string{"b": "2"}},
+		{"no value skipped", []string{"a,b=2"}, map[string]string{"b": "2"}},
+		{"empty key skipped", []string{"=1,b=2"}, map[string]string{"b": "2"}},
+		{"oversized", []string{"a=" + strings.Repeat("x", maxBaggageBytes)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseBaggage(tt.headers)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseBaggage(%q) = %v, want %v", tt.headers, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBaggageMemberLimit(t *testing.T) {
+	members := make([]string, maxBaggageMembers+10)
+	for i := range members {
+		members[i] = "k" + strconv.Itoa(i) + "=v"
+	}
+	if got := parseBaggage(members); len(got) != maxBaggageMembers {
+		t.Errorf("kept %d members, want %d", len(got), maxBaggageMembers)
+	}
+}
+
+func TestFormatBaggageRoundTrip(t *testing.T) {
+	b := map[string]string{"user": "alice", "city": "São Paulo", "note": "a,b;c=d"}
+	got := formatBaggage(b)
+	if want := "city=S%C3%A3o%20Paulo,note=a%2Cb%3Bc=d,user=alice"; got != want {
+		t.Errorf("formatBaggage = %q, want %q", got, want)
+	}
+	if back := parseBaggage([]string{got}); !reflect.DeepEqual(back, b) {
+		t.Errorf("round trip = %v, want %v", back, b)
+	}
+}
+
+func TestPropagateBaggage(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		outgoing string // baggage already on the outgoing request
+		want     string
+	}{
+		{"no baggage", "", "", ""},
+		{"forwarded", "user=alice, tier=gold;p=1", "", "tier=gold,user=alice"},
+		{"outgoing header kept", "user=alice", "user=bob", "user=bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get(baggageHeader)
+			}))
+			defer upstream.Close()
+			client := &http.Client{Transport: PropagateRequestID(nil)}
+
+			r := gin.New()
+			r.Use(Baggage())
+			r.GET("/x", func(c *gin.Context) {
+				req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, upstream.URL, nil)
+				if tt.outgoing != "" {
+					req.Header.Set(baggageHeader, tt.outgoing)
+				}
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			if tt.incoming != "" {
+				req.Header.Set(baggageHeader, tt.incoming)
+			}
+			serve(r, req)
+
+			if got != tt.want {
+				t.Errorf("upstream baggage = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// WithBodyHash makes RequestLogger log a hex SHA-256 body_hash of the request
+// body at every level, truncated to length characters when length > 0. With
+// hideBody the raw body is never logged, even at debug.
+func WithBodyHash(length int, hideBody bool) Option {
+	return func(o *options) {
+		o.bodyHash = true
+		o.bodyHashLen = length
+		o.hideBody = hideBody
+	}
+}
+
+func (o *options) hashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return o.formatHash(sum[:])
+}
+
+func (o *options) formatHash(sum []byte) string {
+	h := hex.EncodeToString(sum)
+	if o.bodyHashLen > 0 && o.bodyHashLen < len(h) {
+		h = h[:o.bodyHashLen]
+	}
+	return h
+}
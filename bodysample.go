@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"math/rand"
+
+	"github.com/gin-gonic/gin"
+)
+
+const bodySampledKey = "body_sampled"
+
+// WithBodySampleRate logs request and response bodies for only the given
+// fraction (0..1) of requests to the route path. Unsampled requests skip body
+// reading and buffering entirely.
+func WithBodySampleRate(path string, rate float64) Option {
+	return func(o *options) {
+		if o.bodySampleRate == nil {
+			o.bodySampleRate = map[string]float64{}
+		}
+		o.bodySampleRate[path] = rate
+	}
+}
+
+// sampleBody decides once per request so RequestLogger and ResponseLogger agree.
+func (o *options) sampleBody(c *gin.Context) bool {
+	if v, ok := c.Get(bodySampledKey); ok {
+		return v.(bool)
+	}
+	if o.traceSampling && traceUnsampled(c) {
+		c.Set(bodySampledKey, false)
+		return false
+	}
+	rate, ok := o.bodySampleRate[c.FullPath()]
+	if !ok {
+		return true
+	}
+	sampled := rand.Float64() < rate
+	c.Set(bodySampledKey, sampled)
+	return sampled
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRequestLoggerBodySampleRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		rate     float64
+		wantBody bool
+	}{
+		{"rate 1", "/sampled", 1, true},
+		{"rate 0", "/sampled", 0, false},
+		{"other route", "/other", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			var handlerBody string
+			r := gin.New()
+			r.Use(RequestLogger(logger, WithBodySampleRate("/sampled", tt.rate)))
+			handler := func(c *gin.Context) {
+				b, _ := io.ReadAll(c.Request.Body)
+				handlerBody = string(b)
+			}
+			r.POST("/sampled", handler)
+			r.POST("/other", handler)
+
+			serve(r, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(`{"a":1}`)))
+
+			if handlerBody != `{"a":1}` {
+				t.Errorf("handler read %q", handlerBody)
+			}
+			logged := false
+			for _, e := range logs.All() {
+				if body, ok := e.ContextMap()["body"]; ok && body == `{"a":1}` {
+					logged = true
+				}
+			}
+			if logged != tt.wantBody {
+				t.Errorf("body logged = %v, want %v", logged, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Bot matches a User-Agent containing Match (case-insensitive).
+type Bot struct {
+	Name  string
+	Match string
+}
+
+// DefaultBots is used by WithBotDetection when no bots are given. The generic
+// entries go last so named crawlers win.
+var DefaultBots = []Bot{
+	{Name: "googlebot", Match: "googlebot"},
+	{Name: "bingbot", Match: "bingbot"},
+	{Name: "yahoo", Match: "slurp"},
+	{Name: "duckduckbot", Match: "duckduckbot"},
+	{Name: "baiduspider", Match: "baiduspider"},
+	{Name: "yandexbot", Match: "yandexbot"},
+	{Name: "applebot", Match: "applebot"},
+	{Name: "facebook", Match: "facebookexternalhit"},
+	{Name: "twitterbot", Match: "twitterbot"},
+	{Name: "linkedinbot", Match: "linkedinbot"},
+	{Name: "ahrefsbot", Match: "ahrefsbot"},
+	{Name: "semrushbot", Match: "semrushbot"},
+	{Name: "mj12bot", Match: "mj12bot"},
+	{Name: "gptbot", Match: "gptbot"},
+	{Name: "bot", Match: "bot"},
+	{Name: "crawler", Match: "crawler"},
+	{Name: "spider", Match: "spider"},
+}
+
+// WithBotDetection adds is_bot and bot fields to the api summary when the
+// User-Agent matches one of bots, or DefaultBots if none are given.
+func WithBotDetection(bots ...Bot) Option {
+	if len(bots) == 0 {
+		bots = DefaultBots
+	}
+	matchers := make([]Bot, len(bots))
+	for i, b := range bots {
+		matchers[i] = Bot{Name: b.Name, Match: strings.ToLower(b.Match)}
+	}
+	return func(o *options) {
+		o.bots = matchers
+	}
+}
+
+func (o *options) botFields(c *gin.Context) []zap.Field {
+	ua := strings.ToLower(c.Request.UserAgent())
+	if ua == "" {
+		return nil
+	}
+	for _, b := range o.bots {
+		if strings.Contains(ua, b.Match) {
+			return []zap.Field{zap.Bool("is_bot", true), zap.String("bot", b.Name)}
+		}
+	}
+	return nil
+}
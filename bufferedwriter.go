@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bufferedResponseWriter holds the status, headers and body in memory instead
+// of writing them through, so a middleware can inspect or rewrite the response
+// after c.Next() and then flush it.
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	header  http.Header
+	body    *bytes.Buffer
+	status  int
+	written bool
+	// max, when positive, is how much body to hold; past it the writer
+	// flushes what it has and streams the rest through.
+	max       int
+	streaming bool
+}
+
+func newBufferedResponseWriter(w gin.ResponseWriter) *bufferedResponseWriter {
+	return &bufferedResponseWriter{
+		ResponseWriter: w,
+		header:         w.Header().Clone(),
+		body:           &bytes.Buffer{},
+		status:         http.StatusOK,
+	}
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	if w.overflows(len(b)) {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	w.written = true
+	if w.overflows(len(s)) {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+// overflows reports whether n more bytes go straight to the client, switching
+// to streaming when they would take the body past max.
+func (w *bufferedResponseWriter) overflows(n int) bool {
+	if w.streaming {
+		return true
+	}
+	if w.max <= 0 || w.body.Len()+n <= w.max {
+		return false
+	}
+	w.flush()
+	w.streaming = true
+	return true
+}
+
+func (w *bufferedResponseWriter) Status() int {
+	if w.streaming {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *bufferedResponseWriter) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedResponseWriter) Written() bool {
+	return w.written
+}
+
+func (w *bufferedResponseWriter) Flush() {
+	if w.streaming {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *bufferedResponseWriter) flush() {
+	if w.streaming {
+		return
+	}
+	dst := w.ResponseWriter.Header()
+	for k := range dst {
+		if _, ok := w.header[k]; !ok {
+			delete(dst, k)
+		}
+	}
+	for k, v := range w.header {
+		dst[k] = v
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.ResponseWriter.Write(w.body.Bytes())
+}
+
+// nextBuffered runs the rest of the chain against w. The original writer is
+// put back even when a handler panics, so an outer Recovery's 500 reaches the
+// client instead of a buffer nobody flushes.
+func nextBuffered(c *gin.Context, w *bufferedResponseWriter) {
+	c.Writer = w
+	defer func() { c.Writer = w.ResponseWriter }()
+	c.Next()
+}
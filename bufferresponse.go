@@ -0,0 +1,25 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// BufferResponse holds the whole response until the handlers are done, so a
+// handler can still change the status or headers after writing some of the
+// body. Once the body would pass maxBytes (when positive) what is held is
+// sent and the rest streams through as usual. It changes streaming semantics,
+// so attach it only to the routes that need it.
+func BufferResponse(maxBytes int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := newBufferedResponseWriter(c.Writer)
+		w.max = maxBytes
+		c.Writer = w
+		defer func() {
+			c.Writer = w.ResponseWriter
+			if r := recover(); r != nil {
+				// Drop what was held so the recovery middleware can answer.
+				panic(r)
+			}
+			w.flush()
+		}()
+		c.Next()
+	}
+}
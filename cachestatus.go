@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const cacheStatusKey = "cache_status"
+
+// SetCacheStatus records the cache outcome of the request, e.g. "HIT" or
+// "MISS", for WithCacheStatus.
+func SetCacheStatus(c *gin.Context, status string) {
+	c.Set(cacheStatusKey, status)
+}
+
+// WithCacheStatus adds the status recorded by SetCacheStatus to the api
+// summary as cache_status.
+func WithCacheStatus() Option {
+	return func(o *options) {
+		o.cacheStatus = true
+	}
+}
+
+func cacheStatusFields(c *gin.Context) []zap.Field {
+	if s := c.GetString(cacheStatusKey); s != "" {
+		return []zap.Field{zap.String("cache_status", s)}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const nonCanonicalJSONMsg = "non_canonical_json"
+
+// CanonicalJSON warns about JSON responses whose keys are not in sorted
+// order, which breaks body-hash cache keys when a handler serializes a map or
+// struct inconsistently. With rewrite the response is buffered and re-encoded
+// with sorted keys instead; numbers keep their original text.
+func CanonicalJSON(logger *zap.Logger, rewrite bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !rewrite {
+			w := &responseBodyWriter{body: &bytes.Buffer{}, ResponseWriter: c.Writer}
+			c.Writer = w
+			c.Next()
+			c.Writer = w.ResponseWriter
+			if isJSONContentType(w.Header().Get("Content-Type")) {
+				if _, ok := canonicalJSON(w.body.Bytes()); !ok {
+					logNonCanonicalJSON(logger, c, false)
+				}
+			}
+			return
+		}
+
+		w := newBufferedResponseWriter(c.Writer)
+		nextBuffered(c, w)
+		if isJSONContentType(w.Header().Get("Content-Type")) {
+			if canon, ok := canonicalJSON(w.body.Bytes()); !ok && canon != nil {
+				logNonCanonicalJSON(logger, c, true)
+				w.body.Reset()
+				w.body.Write(canon)
+				w.Header().Del("Content-Length")
+			}
+		}
+		w.flush()
+	}
+}
+
+func logNonCanonicalJSON(logger *zap.Logger, c *gin.Context, rewritten bool) {
+	logger.Warn(nonCanonicalJSONMsg,
+		zap.String("xid", getRequestID(c)),
+		zap.String("method", c.Request.Method),
+		zap.String("path_uri", routePath(c)),
+		zap.Bool("rewritten", rewritten),
+	)
+}
+
+// canonicalJSON reports whether the object keys in body are in sorted order
+// and, when they are not, returns body re-encoded with sorted keys. Only key
+// order counts: whitespace and string escapes, such as a literal "<" or a
+// \u00e9, are left alone. Invalid JSON counts as canonical, with a nil
+// result, since there is nothing to reorder.
+func canonicalJSON(body []byte) ([]byte, bool) {
+	if !json.Valid(body) || keysSorted(body) {
+		return nil, true
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, true
+	}
+	var canon bytes.Buffer
+	enc := json.NewEncoder(&canon)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, true
+	}
+	return bytes.TrimSuffix(canon.Bytes(), []byte("\n")), false
+}
+
+// keysSorted walks the tokens of valid JSON and reports whether every
+// object's keys are strictly increasing, the order json.Marshal writes.
+func keysSorted(body []byte) bool {
+	type frame struct {
+		object    bool
+		expectKey bool
+		lastKey   *string
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var stack []*frame
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return true
+		}
+		var top *frame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if top != nil && top.object && top.expectKey {
+			if key, ok := tok.(string); ok {
+				if top.lastKey != nil && key <= *top.lastKey {
+					return false
+				}
+				top.lastKey = &key
+				top.expectKey = false
+				continue
+			}
+		}
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &frame{object: true, expectKey: true})
+			continue
+		case json.Delim('['):
+			stack = append(stack, &frame{})
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return true
+			}
+			top = stack[len(stack)-1]
+		}
+		if top != nil && top.object {
+			top.expectKey = true
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	jsonBody := func(body string) gin.HandlerFunc {
+		return func(c *gin.Context) { c.Data(http.StatusOK, gin.MIMEJSON, []byte(body)) }
+	}
+	tests := []struct {
+		name          string
+		handler       gin.HandlerFunc
+		wantWarn      bool
+		wantRewritten string // body in rewrite mode
+		wantStatus    int
+	}{
+		{"sorted", jsonBody(`{"a":1,"b":2}`), false, `{"a":1,"b":2}`, http.StatusOK},
+		{"sorted with whitespace", jsonBody(`{ "a": 1, "b": 2 }`), false, `{ "a": 1, "b": 2 }`, http.StatusOK},
+		{"unsorted", jsonBody(`{"b":2,"a":1}`), true, `{"a":1,"b":2}`, http.StatusOK},
+		{"nested unsorted", jsonBody(`[{"z":{"y":1,"x":2}}]`), true, `[{"z":{"x":2,"y":1}}]`, http.StatusOK},
+		{"numbers kept", jsonBody(`{"b":1.50,"a":1e3}`), true, `{"a":1e3,"b":1.50}`, http.StatusOK},
+		{"html characters", jsonBody(`{"a":"<b>&amp;</b>","b":1}`), false, `{"a":"<b>&amp;</b>","b":1}`, http.StatusOK},
+		{"html characters, unsorted", jsonBody(`{"b":1,"a":"<b>"}`), true, `{"a":"<b>","b":1}`, http.StatusOK},
+		{"escaped html", jsonBody(`{"a":"\u003cb\u003e","b":1}`), false, `{"a":"\u003cb\u003e","b":1}`, http.StatusOK},
+		{"escaped unicode key", jsonBody(`{"z":1,"\u00e9":2}`), false, `{"z":1,"\u00e9":2}`, http.StatusOK},
+		{"escaped unicode key, unsorted", jsonBody(`{"\u00e9":1,"a":2}`), true, `{"a":2,"é":1}`, http.StatusOK},
+		{"duplicate keys", jsonBody(`{"a":1,"a":2}`), true, `{"a":2}`, http.StatusOK},
+		{"sorted after nested object", jsonBody(`{"a":{"z":1},"b":[{"y":1,"z":2}],"c":3}`), false, `{"a":{"z":1},"b":[{"y":1,"z":2}],"c":3}`, http.StatusOK},
+		{"pure json", func(c *gin.Context) { c.PureJSON(http.StatusOK, gin.H{"a": "<b>", "b": "&"}) }, false, "{\"a\":\"<b>\",\"b\":\"&\"}\n", http.StatusOK},
+		{"invalid json", jsonBody(`{"b":`), false, `{"b":`, http.StatusOK},
+		{"not json", func(c *gin.Context) { c.String(http.StatusOK, `{"b":2,"a":1}`) }, false, `{"b":2,"a":1}`, http.StatusOK},
+		{"panic", func(c *gin.Context) { panic("boom") }, false, "", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		for _, rewrite := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/rewrite=%v", tt.name, rewrite), func(t *testing.T) {
+				logger, logs := observedLogger(zapcore.DebugLevel)
+				r := gin.New()
+				r.Use(gin.Recovery(), CanonicalJSON(logger, rewrite))
+				r.GET("/x", tt.handler)
+
+				w := serve(r, httptest.NewRequest(http.MethodGet, "/x", nil))
+
+				if w.Code != tt.wantStatus {
+					t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+				}
+				if got := logs.FilterMessage(nonCanonicalJSONMsg).Len() > 0; got != tt.wantWarn {
+					t.Errorf("warned = %v, want %v", got, tt.wantWarn)
+				}
+				if rewrite && w.Body.String() != tt.wantRewritten {
+					t.Errorf("body = %s, want %s", w.Body.String(), tt.wantRewritten)
+				}
+			})
+		}
+	}
+}
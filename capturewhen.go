@@ -0,0 +1,15 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// WithCaptureBodyWhen lets fn decide whether ResponseLogger captures the
+// response body. It is called before the capturing writer is installed, when
+// only the request is known, and again on the first write, when the handler
+// has set its headers (say Content-Type: text/event-stream). A false before
+// leaves c.Writer untouched; a false after stops capturing and logging for
+// the request.
+func WithCaptureBodyWhen(fn func(c *gin.Context) bool) Option {
+	return func(o *options) {
+		o.captureBodyWhen = fn
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestResponseLoggerCaptureBodyWhen(t *testing.T) {
+	notStreaming := func(c *gin.Context) bool {
+		return c.Writer.Header().Get("Content-Type") != "text/event-stream"
+	}
+	tests := []struct {
+		name        string
+		when        func(c *gin.Context) bool
+		contentType string
+		want        bool
+	}{
+		{"always", func(*gin.Context) bool { return true }, "application/json", true},
+		{"never", func(*gin.Context) bool { return false }, "application/json", false},
+		{"by request", func(c *gin.Context) bool { return c.Query("log") == "1" }, "application/json", false},
+		{"by response, json", notStreaming, "application/json", true},
+		{"by response, stream", notStreaming, "text/event-stream", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			r := gin.New()
+			r.Use(ResponseLogger(logger, WithCaptureBodyWhen(tt.when)))
+			r.GET("/x", func(c *gin.Context) {
+				c.Data(http.StatusOK, tt.contentType, []byte("data"))
+			})
+
+			w := serve(r, httptest.NewRequest(http.MethodGet, "/x", nil))
+
+			if w.Body.String() != "data" {
+				t.Errorf("client got %q", w.Body.String())
+			}
+			if got := logs.FilterMessage(responseInfoMsg).Len() == 1; got != tt.want {
+				t.Errorf("response logged = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// The recheck can only turn capturing off: a writer installed only to spot
+// late writes must not start buffering because the predicate passes.
+func TestResponseBodyWriterRecheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		skipped bool
+		recheck bool
+		want    string
+	}{
+		{"capturing, kept", false, true, "data"},
+		{"capturing, stopped", false, false, ""},
+		{"skipped, passes", true, true, ""},
+		{"skipped, fails", true, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			w := &responseBodyWriter{
+				ResponseWriter: c.Writer,
+				body:           &bytes.Buffer{},
+				skipped:        tt.skipped,
+				recheck:        func() bool { return tt.recheck },
+			}
+			w.Write([]byte("da"))
+			w.Write([]byte("ta"))
+
+			if got := w.body.String(); got != tt.want {
+				t.Errorf("captured %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
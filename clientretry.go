@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WithClientRetry makes Logger log client_retry from the retry-count request
+// header the client sends, X-Retry-Count when header is empty. Absent or
+// non-numeric values get no field.
+func WithClientRetry(header string) Option {
+	if header == "" {
+		header = "X-Retry-Count"
+	}
+	return func(o *options) {
+		o.retryHeader = header
+	}
+}
+
+func (o *options) clientRetryFields(c *gin.Context) []zap.Field {
+	n, err := strconv.Atoi(c.GetHeader(o.retryHeader))
+	if err != nil || n < 0 {
+		return nil
+	}
+	return []zap.Field{zap.Int("client_retry", n)}
+}
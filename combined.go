@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithCombinedLog makes Logger emit a single line per request. Request and
+// response bodies are captured by Logger itself, under the same level,
+// sampling and forced-debug rules as RequestLogger and ResponseLogger, and
+// nested as request and response objects. Use it instead of those two
+// middlewares, not alongside them.
+func WithCombinedLog() Option {
+	return func(o *options) {
+		o.combined = true
+	}
+}
+
+type bodyCapture struct {
+	header []byte
+	body   []byte
+	w      *responseBodyWriter
+}
+
+// startBodyCapture returns nil when bodies are not logged for this request.
+func (o *options) startBodyCapture(logger *zap.Logger, c *gin.Context) *bodyCapture {
+	if !o.forceDebug(c) && (logger.Level() == zapcore.InfoLevel || !o.sampleBody(c)) {
+		return nil
+	}
+	header, _ := json.Marshal(c.Request.Header)
+	bc := &bodyCapture{
+		header: header,
+		w:      &responseBodyWriter{body: &bytes.Buffer{}, ResponseWriter: c.Writer},
+	}
+	if !o.hideBody {
+		bc.body = readRequestBody(c)
+	}
+	c.Writer = bc.w
+	return bc
+}
+
+func (bc *bodyCapture) fields(c *gin.Context) []zap.Field {
+	if bc == nil {
+		return nil
+	}
+	c.Set(responseBodyKey, bc.w.body.Bytes())
+	return []zap.Field{
+		zap.Object("request", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("header", string(bc.header))
+			enc.AddString("body", string(bc.body))
+			return nil
+		})),
+		zap.Object("response", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("body", bc.w.body.String())
+			return nil
+		})),
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const concurrencyExceededMsg = "concurrency_exceeded"
+
+type ConcurrencyOptions struct {
+	// Key identifies the client; defaults to c.ClientIP().
+	Key func(c *gin.Context) string
+}
+
+// MaxConcurrentPerClient aborts with 429 when a client already has max
+// requests in flight. Slots are released after c.Next(), even on panic, and a
+// client's entry is dropped as soon as it has nothing in flight, so idle
+// clients hold no memory.
+func MaxConcurrentPerClient(logger *zap.Logger, max int, opts ConcurrencyOptions) gin.HandlerFunc {
+	if opts.Key == nil {
+		opts.Key = func(c *gin.Context) string { return c.ClientIP() }
+	}
+	var (
+		mu       sync.Mutex
+		inFlight = map[string]int{}
+	)
+	return func(c *gin.Context) {
+		key := opts.Key(c)
+		mu.Lock()
+		if inFlight[key] >= max {
+			mu.Unlock()
+			logger.Warn(concurrencyExceededMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("client", key),
+				zap.Int("max", max),
+			)
+			abortWithError(c, http.StatusTooManyRequests, "too many concurrent requests")
+			return
+		}
+		inFlight[key]++
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			if inFlight[key]--; inFlight[key] <= 0 {
+				delete(inFlight, key)
+			}
+			mu.Unlock()
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMaxConcurrentPerClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		max    int
+		held   int    // requests from client "a" kept in flight
+		client string // of the probing request
+		want   int
+	}{
+		{"idle", 2, 0, "a", http.StatusOK},
+		{"below limit", 2, 1, "a", http.StatusOK},
+		{"limit reached", 2, 2, "a", http.StatusTooManyRequests},
+		{"limit of one", 1, 1, "a", http.StatusTooManyRequests},
+		{"other client", 1, 1, "b", http.StatusOK},
+		{"zero max", 0, 0, "a", http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.WarnLevel)
+			entered, release := make(chan struct{}), make(chan struct{})
+			r := gin.New()
+			r.Use(MaxConcurrentPerClient(logger, tt.max, ConcurrencyOptions{
+				Key: func(c *gin.Context) string { return c.GetHeader("X-Client") },
+			}))
+			r.GET("/hold", func(c *gin.Context) {
+				entered <- struct{}{}
+				<-release
+				c.Status(http.StatusOK)
+			})
+			r.GET("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+			request := func(path, client string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				req.Header.Set("X-Client", client)
+				return serve(r, req)
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < tt.held; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					request("/hold", "a")
+				}()
+				<-entered
+			}
+			w := request("/x", tt.client)
+			close(release)
+			wg.Wait()
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			entries := logs.FilterMessage(concurrencyExceededMsg).All()
+			if tt.want == http.StatusOK {
+				if len(entries) != 0 {
+					t.Errorf("logs = %v, want none", summaries(logs))
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("logs = %v, want one %s", summaries(logs), concurrencyExceededMsg)
+			}
+			fields := entries[0].ContextMap()
+			if fields["client"] != tt.client || fields["max"] != int64(tt.max) {
+				t.Errorf("fields = %v", fields)
+			}
+			// Released slots are usable again.
+			if tt.max > 0 {
+				if w := request("/x", tt.client); w.Code != http.StatusOK {
+					t.Errorf("after release: status = %d, want %d", w.Code, http.StatusOK)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxConcurrentPerClientPanic(t *testing.T) {
+	r := gin.New()
+	r.Use(gin.Recovery(), MaxConcurrentPerClient(zap.NewNop(), 1, ConcurrencyOptions{}))
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+	r.GET("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	if w := serve(r, httptest.NewRequest(http.MethodGet, "/panic", nil)); w.Code != http.StatusInternalServerError {
+		t.Fatalf("panic: status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if w := serve(r, httptest.NewRequest(http.MethodGet, "/x", nil)); w.Code != http.StatusOK {
+		t.Errorf("after panic: status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,11 @@
+package middleware
+
+// WithContentLengthCheck makes RequestLogger compare the declared
+// Content-Length with the bytes actually read and warn with
+// content_length_mismatch when they differ. With reject it also aborts with 400.
+func WithContentLengthCheck(reject bool) Option {
+	return func(o *options) {
+		o.checkContentLength = true
+		o.rejectContentLengthMismatch = reject
+	}
+}
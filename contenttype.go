@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const unexpectedContentTypeMsg = "unexpected_content_type"
+
+type ContentTypeOptions struct {
+	// Allowed lists media types without parameters, e.g. "application/json".
+	Allowed []string
+	// Rewrite buffers the response and replaces a disallowed one with a 500
+	// ErrorResponse instead of only logging it.
+	Rewrite bool
+}
+
+// ResponseContentTypes warns when a response is sent with a Content-Type
+// outside opts.Allowed, such as an HTML error page out of a JSON API. Empty
+// responses without a Content-Type are fine.
+func ResponseContentTypes(logger *zap.Logger, opts ContentTypeOptions) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(opts.Allowed))
+	for _, t := range opts.Allowed {
+		allowed[strings.ToLower(t)] = true
+	}
+	check := func(c *gin.Context, header http.Header, size int, status int) bool {
+		ct := header.Get("Content-Type")
+		if ct == "" && size <= 0 {
+			return true
+		}
+		mt, _, _ := mime.ParseMediaType(ct)
+		if allowed[mt] {
+			return true
+		}
+		logger.Warn(unexpectedContentTypeMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("method", c.Request.Method),
+			zap.String("path_uri", routePath(c)),
+			zap.Int("status", status),
+			zap.String("content_type", ct),
+			zap.Bool("rewritten", opts.Rewrite),
+		)
+		return false
+	}
+
+	return func(c *gin.Context) {
+		if !opts.Rewrite {
+			c.Next()
+			check(c, c.Writer.Header(), c.Writer.Size(), c.Writer.Status())
+			return
+		}
+
+		w := newBufferedResponseWriter(c.Writer)
+		nextBuffered(c, w)
+
+		if !check(c, w.Header(), w.body.Len(), w.status) {
+			b, _ := json.Marshal(ErrorResponse{Error: ErrorBody{
+				Code:      http.StatusInternalServerError,
+				Message:   "unexpected response content type",
+				RequestID: getRequestID(c),
+			}})
+			w.status = http.StatusInternalServerError
+			w.body.Reset()
+			w.body.Write(b)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Header().Del("Content-Length")
+		}
+		w.flush()
+	}
+}
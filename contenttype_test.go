@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestResponseContentTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  gin.HandlerFunc
+		wantWarn bool
+	}{
+		{"allowed", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"a": 1}) }, false},
+		{"allowed, other case", func(c *gin.Context) { c.Data(http.StatusOK, "Application/JSON", []byte("{}")) }, false},
+		{"empty without content type", func(c *gin.Context) { c.Status(http.StatusNoContent) }, false},
+		{"html", func(c *gin.Context) { c.Data(http.StatusBadGateway, "text/html", []byte("<h1>oops</h1>")) }, true},
+		{"body without content type", func(c *gin.Context) { c.Writer.Write([]byte("raw")) }, true},
+	}
+	for _, tt := range tests {
+		for _, rewrite := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/rewrite=%v", tt.name, rewrite), func(t *testing.T) {
+				logger, logs := observedLogger(zapcore.DebugLevel)
+				r := gin.New()
+				r.Use(ResponseContentTypes(logger, ContentTypeOptions{Allowed: []string{"application/json"}, Rewrite: rewrite}))
+				r.GET("/x", tt.handler)
+
+				w := serve(r, httptest.NewRequest(http.MethodGet, "/x", nil))
+
+				if got := logs.FilterMessage(unexpectedContentTypeMsg).Len() > 0; got != tt.wantWarn {
+					t.Errorf("warned = %v, want %v", got, tt.wantWarn)
+				}
+				if rewritten := w.Code == http.StatusInternalServerError; rewritten != (rewrite && tt.wantWarn) {
+					t.Errorf("status = %d, body = %s", w.Code, w.Body)
+				}
+			})
+		}
+	}
+}
+
+func TestResponseContentTypesPanic(t *testing.T) {
+	r := gin.New()
+	r.Use(gin.Recovery(), ResponseContentTypes(zap.NewNop(), ContentTypeOptions{Allowed: []string{"application/json"}, Rewrite: true}))
+	r.GET("/x", func(c *gin.Context) { panic("boom") })
+
+	if w := serve(r, httptest.NewRequest(http.MethodGet, "/x", nil)); w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WithContextKeys makes Logger log the values handlers stored under keys with
+// c.Set, as ctx.<key> fields. Keys that were never set are omitted. gin 1.9
+// only has string keys, hence string rather than any.
+func WithContextKeys(keys ...string) Option {
+	return func(o *options) {
+		o.contextKeys = append(o.contextKeys, keys...)
+	}
+}
+
+func (o *options) contextKeyFields(c *gin.Context) []zap.Field {
+	var zf []zap.Field
+	for _, k := range o.contextKeys {
+		if v, ok := c.Get(k); ok {
+			zf = append(zf, zap.Any("ctx."+k, v))
+		}
+	}
+	return zf
+}
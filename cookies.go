@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type cookieMeta struct {
+	Name     string `json:"name"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	MaxAge   int    `json:"max_age,omitempty"`
+	Secure   bool   `json:"secure"`
+	HttpOnly bool   `json:"http_only"`
+	SameSite string `json:"same_site,omitempty"`
+}
+
+// WithSetCookies adds a set_cookies field to ResponseLogger listing the name
+// and attributes of each cookie the response sets, never the value.
+func WithSetCookies() Option {
+	return func(o *options) {
+		o.setCookies = true
+	}
+}
+
+func setCookieFields(c *gin.Context) []zap.Field {
+	cookies := (&http.Response{Header: c.Writer.Header()}).Cookies()
+	if len(cookies) == 0 {
+		return nil
+	}
+	meta := make([]cookieMeta, len(cookies))
+	for i, ck := range cookies {
+		meta[i] = cookieMeta{
+			Name:     ck.Name,
+			Path:     ck.Path,
+			Domain:   ck.Domain,
+			MaxAge:   ck.MaxAge,
+			Secure:   ck.Secure,
+			HttpOnly: ck.HttpOnly,
+			SameSite: sameSiteName(ck.SameSite),
+		}
+		if !ck.Expires.IsZero() {
+			meta[i].Expires = ck.Expires.UTC().Format(time.RFC3339)
+		}
+	}
+	return []zap.Field{zap.Any("set_cookies", meta)}
+}
+
+func sameSiteName(s http.SameSite) string {
+	switch s {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	default:
+		return ""
+	}
+}
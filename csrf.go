@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	csrfRejectedMsg = "csrf_rejected"
+	csrfErrorMsg    = "csrf_store_error"
+	csrfTokenKey    = "csrf_token"
+)
+
+// CSRFStore keeps the synchronizer token of each session, e.g. in the session
+// store the app already has.
+type CSRFStore interface {
+	Get(ctx context.Context, session string) (string, error)
+	Put(ctx context.Context, session string, token string) error
+}
+
+type CSRFOptions struct {
+	// Secret signs tokens.
+	Secret []byte
+	// Store switches from the double-submit cookie to synchronizer tokens
+	// kept per Session, which must then be set.
+	Store   CSRFStore
+	Session func(c *gin.Context) string
+	// Cookie defaults to "csrf_token", Header to "X-CSRF-Token" and Field,
+	// the form field checked when the header is absent, to "csrf_token".
+	Cookie string
+	Header string
+	Field  string
+}
+
+// CSRF aborts unsafe requests with 403 unless they submit, in opts.Header or
+// the urlencoded form field opts.Field, a validly signed token that matches
+// the one issued to the client: in the opts.Cookie cookie by default, or in
+// opts.Store. Safe methods are exempt and get a token issued when they have
+// none. Handlers read it with CSRFToken to embed in forms. The form is read
+// from a copy of the body, which the handler still gets in full.
+func CSRF(logger *zap.Logger, opts CSRFOptions) gin.HandlerFunc {
+	if opts.Store != nil && opts.Session == nil {
+		panic("middleware: CSRFOptions.Store requires Session")
+	}
+	if opts.Cookie == "" {
+		opts.Cookie = "csrf_token"
+	}
+	if opts.Header == "" {
+		opts.Header = "X-CSRF-Token"
+	}
+	if opts.Field == "" {
+		opts.Field = "csrf_token"
+	}
+	return func(c *gin.Context) {
+		issued, err := opts.issuedToken(c)
+		if err != nil {
+			logger.Error(csrfErrorMsg, zap.String("xid", getRequestID(c)), zap.Error(err))
+			abortWithError(c, http.StatusInternalServerError, "csrf token unavailable")
+			return
+		}
+		valid := issued != "" && validCSRFToken(opts.Secret, issued)
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			if !valid {
+				issued = newCSRFToken(opts.Secret)
+				if err := opts.issue(c, issued); err != nil {
+					logger.Error(csrfErrorMsg, zap.String("xid", getRequestID(c)), zap.Error(err))
+				}
+			}
+			c.Set(csrfTokenKey, issued)
+			c.Next()
+			return
+		}
+
+		submitted := opts.submittedToken(c)
+		if !valid || submitted == "" || !hmac.Equal([]byte(submitted), []byte(issued)) {
+			logger.Warn(csrfRejectedMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("method", c.Request.Method),
+				zap.String("path_uri", routePath(c)),
+				zap.Bool("token_issued", issued != ""),
+				zap.Bool("token_submitted", submitted != ""),
+			)
+			abortWithError(c, http.StatusForbidden, "invalid csrf token")
+			return
+		}
+		c.Set(csrfTokenKey, issued)
+		c.Next()
+	}
+}
+
+// CSRFToken returns the token CSRF issued or accepted for the request.
+func CSRFToken(c *gin.Context) string {
+	return c.GetString(csrfTokenKey)
+}
+
+func (o CSRFOptions) issuedToken(c *gin.Context) (string, error) {
+	if o.Store != nil {
+		return o.Store.Get(c.Request.Context(), o.Session(c))
+	}
+	token, _ := c.Cookie(o.Cookie)
+	return token, nil
+}
+
+func (o CSRFOptions) issue(c *gin.Context, token string) error {
+	if o.Store != nil {
+		return o.Store.Put(c.Request.Context(), o.Session(c), token)
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     o.Cookie,
+		Value:    token,
+		Path:     "/",
+		Secure:   c.Request.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func (o CSRFOptions) submittedToken(c *gin.Context) string {
+	if token := c.GetHeader(o.Header); token != "" {
+		return token
+	}
+	if c.ContentType() != gin.MIMEPOSTForm {
+		return ""
+	}
+	form, _ := url.ParseQuery(string(readRequestBody(c)))
+	return form.Get(o.Field)
+}
+
+// A token is a random nonce and its HMAC, both base64url: "<nonce>.<mac>".
+func newCSRFToken(secret []byte) string {
+	nonce := make([]byte, 32)
+	rand.Read(nonce)
+	n := base64.RawURLEncoding.EncodeToString(nonce)
+	return n + "." + csrfMAC(secret, n)
+}
+
+func validCSRFToken(secret []byte, token string) bool {
+	n, mac, ok := strings.Cut(token, ".")
+	return ok && hmac.Equal([]byte(mac), []byte(csrfMAC(secret, n)))
+}
+
+func csrfMAC(secret []byte, nonce string) string {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(nonce))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var testCSRFSecret = []byte("csrf-secret")
+
+type memCSRFStore struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func (s *memCSRFStore) Get(_ context.Context, session string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[session], nil
+}
+
+func (s *memCSRFStore) Put(_ context.Context, session string, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[session] = token
+	return nil
+}
+
+func TestCSRFCookie(t *testing.T) {
+	valid := newCSRFToken(testCSRFSecret)
+	forged := newCSRFToken([]byte("other"))
+	tests := []struct {
+		name       string
+		method     string
+		cookie     string
+		header     string
+		form       string
+		wantStatus int
+		wantBody   string // what the handler reads back
+	}{
+		{"safe method issues", http.MethodGet, "", "", "", http.StatusOK, ""},
+		{"no cookie", http.MethodPost, "", valid, "", http.StatusForbidden, ""},
+		{"no submitted token", http.MethodPost, valid, "", "", http.StatusForbidden, ""},
+		{"header matches", http.MethodPost, valid, valid, "", http.StatusOK, ""},
+		{"header differs", http.MethodPost, valid, newCSRFToken(testCSRFSecret), "", http.StatusForbidden, ""},
+		{"forged cookie", http.MethodPost, forged, forged, "", http.StatusForbidden, ""},
+		{"form field", http.MethodPost, valid, "", "a=1&csrf_token=" + valid, http.StatusOK, "a=1&csrf_token=" + valid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody, gotToken string
+			r := gin.New()
+			r.Use(CSRF(zap.NewNop(), CSRFOptions{Secret: testCSRFSecret}))
+			r.Handle(tt.method, "/x", func(c *gin.Context) {
+				b, _ := io.ReadAll(c.Request.Body)
+				gotBody, gotToken = string(b), CSRFToken(c)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/x", strings.NewReader(tt.form))
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "csrf_token", Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set("X-CSRF-Token", tt.header)
+			}
+			if tt.form != "" {
+				req.Header.Set("Content-Type", gin.MIMEPOSTForm)
+			}
+			w := serve(r, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			if !validCSRFToken(testCSRFSecret, gotToken) {
+				t.Errorf("CSRFToken = %q, want a valid token", gotToken)
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("handler read %q, want %q", gotBody, tt.wantBody)
+			}
+			if issued := w.Result().Cookies(); tt.cookie == "" && (len(issued) != 1 || issued[0].Value != gotToken) {
+				t.Errorf("issued cookies = %v, want the CSRFToken", issued)
+			}
+		})
+	}
+}
+
+func TestCSRFStore(t *testing.T) {
+	store := &memCSRFStore{tokens: map[string]string{}}
+	r := gin.New()
+	r.Use(CSRF(zap.NewNop(), CSRFOptions{
+		Secret:  testCSRFSecret,
+		Store:   store,
+		Session: func(c *gin.Context) string { return c.GetHeader("X-Session") },
+	}))
+	var issued string
+	r.GET("/x", func(c *gin.Context) { issued = CSRFToken(c) })
+	r.POST("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/x", nil)
+	req.Header.Set("X-Session", "s1")
+	serve(r, req)
+	if issued == "" || store.tokens["s1"] != issued {
+		t.Fatalf("issued %q, stored %q", issued, store.tokens["s1"])
+	}
+
+	tests := []struct {
+		name       string
+		session    string
+		wantStatus int
+	}{
+		{"own session", "s1", http.StatusOK},
+		{"other session", "s2", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/x", nil)
+			req.Header.Set("X-Session", tt.session)
+			req.Header.Set("X-CSRF-Token", issued)
+			if w := serve(r, req); w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCSRFStoreRequiresSession(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("CSRF accepted a Store without Session")
+		}
+	}()
+	CSRF(zap.NewNop(), CSRFOptions{Secret: testCSRFSecret, Store: &memCSRFStore{}})
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type dbCallsKey struct{}
+
+// WithDBCalls makes Logger put a call counter on the request context and log
+// it as db_calls. The data layer bumps it with IncDBCalls.
+func WithDBCalls() Option {
+	return func(o *options) {
+		o.dbCalls = true
+	}
+}
+
+// IncDBCalls counts one call against the request that ctx belongs to. It is a
+// no-op outside a request logged with WithDBCalls.
+func IncDBCalls(ctx context.Context) {
+	ctx = requestContext(ctx)
+	if n, ok := ctx.Value(dbCallsKey{}).(*atomic.Int64); ok {
+		n.Add(1)
+	}
+}
+
+func startDBCalls(c *gin.Context) *atomic.Int64 {
+	n := &atomic.Int64{}
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), dbCallsKey{}, n))
+	return n
+}
+
+func dbCallsFields(n *atomic.Int64) []zap.Field {
+	if n == nil {
+		return nil
+	}
+	return []zap.Field{zap.Int64("db_calls", n.Load())}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WithDeadline makes Logger log deadline_ms, the budget left on the request
+// when it arrived, and deadline_exceeded once the handlers are done. The
+// deadline comes from the request context or, failing that, a grpc-timeout
+// header. Requests without either get neither field.
+func WithDeadline() Option {
+	return func(o *options) {
+		o.deadline = true
+	}
+}
+
+type requestDeadline struct {
+	at        time.Time
+	remaining time.Duration
+}
+
+func startDeadline(c *gin.Context, start time.Time) *requestDeadline {
+	if at, ok := c.Request.Context().Deadline(); ok {
+		return &requestDeadline{at: at, remaining: at.Sub(start)}
+	}
+	if d, ok := parseGRPCTimeout(c.GetHeader("grpc-timeout")); ok {
+		return &requestDeadline{at: start.Add(d), remaining: d}
+	}
+	return nil
+}
+
+func (d *requestDeadline) fields() []zap.Field {
+	if d == nil {
+		return nil
+	}
+	return []zap.Field{
+		zap.Int64("deadline_ms", d.remaining.Milliseconds()),
+		zap.Bool("deadline_exceeded", !time.Now().Before(d.at)),
+	}
+}
+
+// parseGRPCTimeout parses the gRPC wire format: up to 8 digits and a unit.
+func parseGRPCTimeout(v string) (time.Duration, bool) {
+	if len(v) < 2 || len(v) > 9 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour, 'M': time.Minute, 'S': time.Second,
+		'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond,
+	}
+	unit, ok := units[v[len(v)-1]]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
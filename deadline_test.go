@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseGRPCTimeout(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"1H", time.Hour, true},
+		{"2M", 2 * time.Minute, true},
+		{"30S", 30 * time.Second, true},
+		{"250m", 250 * time.Millisecond, true},
+		{"100u", 100 * time.Microsecond, true},
+		{"5n", 5, true},
+		{"0S", 0, true},
+		{"99999999S", 99999999 * time.Second, true},
+		{"", 0, false},
+		{"S", 0, false},
+		{"5", 0, false},
+		{"5s", 0, false},
+		{"5x", 0, false},
+		{"-5S", 0, false},
+		{"1.5S", 0, false},
+		{"123456789S", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseGRPCTimeout(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseGRPCTimeout(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLoggerDeadline(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       string
+		wantFields   bool
+		wantExceeded bool
+	}{
+		{"no deadline", "", false, false},
+		{"invalid header", "soon", false, false},
+		{"within budget", "10S", true, false},
+		{"exceeded", "1n", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			r := gin.New()
+			r.Use(Logger(logger, WithDeadline()))
+			r.GET("/x", func(c *gin.Context) {
+				time.Sleep(time.Millisecond)
+				c.Status(http.StatusNoContent)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			if tt.header != "" {
+				req.Header.Set("grpc-timeout", tt.header)
+			}
+			serve(r, req)
+
+			got := summaries(logs)
+			if len(got) != 1 {
+				t.Fatalf("got %d api summaries, want 1", len(got))
+			}
+			fields := got[0].ContextMap()
+			exceeded, ok := fields["deadline_exceeded"]
+			if ok != tt.wantFields {
+				t.Fatalf("deadline_exceeded present = %v, want %v", ok, tt.wantFields)
+			}
+			if _, ok := fields["deadline_ms"]; ok != tt.wantFields {
+				t.Errorf("deadline_ms present = %v, want %v", ok, tt.wantFields)
+			}
+			if ok && exceeded != tt.wantExceeded {
+				t.Errorf("deadline_exceeded = %v, want %v", exceeded, tt.wantExceeded)
+			}
+		})
+	}
+}
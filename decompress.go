@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	bodyTooLargeMsg        = "decompressed_body_too_large"
+	defaultMaxDecompressed = 10 << 20
+)
+
+var ErrBodyTooLarge = errors.New("middleware: decompressed body too large")
+
+type DecompressOptions struct {
+	// MaxSize bounds the decompressed body; defaults to 10 MiB.
+	MaxSize int64
+	// Stream decompresses lazily instead of up front. Exceeding MaxSize then
+	// surfaces to the handler as ErrBodyTooLarge from Read rather than a 413.
+	Stream bool
+}
+
+// GzipRequest transparently decompresses gzip request bodies, so handlers and
+// RequestLogger placed after it see plaintext, and aborts with 413 once the
+// decompressed size exceeds opts.MaxSize.
+func GzipRequest(logger *zap.Logger, opts DecompressOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Content-Encoding") != "gzip" {
+			c.Next()
+			return
+		}
+		zr, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, "invalid gzip body")
+			return
+		}
+		if decompressBody(logger, c, zr, opts) {
+			c.Next()
+		}
+	}
+}
+
+// DecompressRequest is GzipRequest for every encoding we support: gzip,
+// deflate and br. Bodies in any other encoding are rejected with 415.
+func DecompressRequest(logger *zap.Logger, opts DecompressOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			r   io.Reader
+			err error
+		)
+		switch enc := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding"))); enc {
+		case "", "identity":
+			c.Next()
+			return
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(c.Request.Body)
+		case "deflate":
+			r, err = zlib.NewReader(c.Request.Body)
+		case "br":
+			r = brotli.NewReader(c.Request.Body)
+		default:
+			abortWithError(c, http.StatusUnsupportedMediaType, "unsupported content encoding "+enc)
+			return
+		}
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, "invalid compressed body")
+			return
+		}
+		if decompressBody(logger, c, r, opts) {
+			c.Next()
+		}
+	}
+}
+
+// decompressBody replaces the request body with r bounded by opts.MaxSize. It
+// reports false when it has aborted the request.
+func decompressBody(logger *zap.Logger, c *gin.Context, r io.Reader, opts DecompressOptions) bool {
+	max := opts.MaxSize
+	if max <= 0 {
+		max = defaultMaxDecompressed
+	}
+	c.Request.Header.Del("Content-Encoding")
+
+	if opts.Stream {
+		c.Request.Body = readCloser{&maxSizeReader{r: r, n: max}, c.Request.Body}
+		c.Request.ContentLength = -1
+		c.Request.Header.Del("Content-Length")
+		return true
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r, max+1))
+	if int64(len(body)) > max {
+		logger.Warn(bodyTooLargeMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("method", c.Request.Method),
+			zap.String("path_uri", routePath(c)),
+			zap.Int64("max_size", max),
+		)
+		abortWithError(c, http.StatusRequestEntityTooLarge, "decompressed body too large")
+		return false
+	}
+	if err != nil {
+		abortWithError(c, http.StatusBadRequest, "invalid compressed body")
+		return false
+	}
+	c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return true
+}
+
+// maxSizeReader fails with ErrBodyTooLarge once more than n bytes are read.
+type maxSizeReader struct {
+	r io.Reader
+	n int64
+}
+
+func (m *maxSizeReader) Read(p []byte) (int, error) {
+	if m.n < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	if int64(len(p)) > m.n+1 {
+		p = p[:m.n+1]
+	}
+	n, err := m.r.Read(p)
+	m.n -= int64(n)
+	if m.n < 0 {
+		return n + int(m.n), ErrBodyTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func gzipBytes(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGzipRequest(t *testing.T) {
+	small := strings.Repeat("a", 100)
+	bomb := strings.Repeat("a", 1000)
+	tests := []struct {
+		name       string
+		encoding   string
+		body       []byte
+		stream     bool
+		wantStatus int
+		wantBody   string
+		wantErr    error
+	}{
+		{"plain", "", []byte("hello"), false, http.StatusOK, "hello", nil},
+		{"gzip", "gzip", gzipBytes(t, small), false, http.StatusOK, small, nil},
+		{"over limit", "gzip", gzipBytes(t, bomb), false, http.StatusRequestEntityTooLarge, "", nil},
+		{"not gzip", "gzip", []byte("hello"), false, http.StatusBadRequest, "", nil},
+		{"truncated", "gzip", gzipBytes(t, small)[:20], false, http.StatusBadRequest, "", nil},
+		{"stream", "gzip", gzipBytes(t, small), true, http.StatusOK, small, nil},
+		{"stream over limit", "gzip", gzipBytes(t, bomb), true, http.StatusOK, bomb[:500], ErrBodyTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, _ := observedLogger(zapcore.DebugLevel)
+			var (
+				got     []byte
+				readErr error
+			)
+			r := gin.New()
+			r.Use(GzipRequest(logger, DecompressOptions{MaxSize: 500, Stream: tt.stream}))
+			r.POST("/x", func(c *gin.Context) {
+				if c.GetHeader("Content-Encoding") != "" {
+					t.Error("Content-Encoding left on the decompressed request")
+				}
+				got, readErr = io.ReadAll(c.Request.Body)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/x", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			w := serve(r, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if string(got) != tt.wantBody {
+				t.Errorf("handler read %d bytes, want %d", len(got), len(tt.wantBody))
+			}
+			if !errors.Is(readErr, tt.wantErr) {
+				t.Errorf("handler read error = %v, want %v", readErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+// RequestLogger after GzipRequest must log the plaintext and still hand the
+// stream-mode size error to the handler.
+func TestGzipRequestWithRequestLogger(t *testing.T) {
+	logger, logs := observedLogger(zapcore.DebugLevel)
+	var readErr error
+	r := gin.New()
+	r.Use(GzipRequest(logger, DecompressOptions{MaxSize: 500, Stream: true}), RequestLogger(logger))
+	r.POST("/x", func(c *gin.Context) {
+		_, readErr = io.ReadAll(c.Request.Body)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/x", bytes.NewReader(gzipBytes(t, strings.Repeat("a", 1000))))
+	req.Header.Set("Content-Encoding", "gzip")
+	serve(r, req)
+
+	if !errors.Is(readErr, ErrBodyTooLarge) {
+		t.Errorf("handler read error = %v, want %v", readErr, ErrBodyTooLarge)
+	}
+	logged := false
+	for _, e := range logs.All() {
+		if body, ok := e.ContextMap()["body"].(string); ok && strings.HasPrefix(body, "aaa") {
+			logged = true
+		}
+	}
+	if !logged {
+		t.Error("RequestLogger did not log the decompressed body")
+	}
+}
+
+func TestDecompressRequest(t *testing.T) {
+	const plain = "hello, decompressed world"
+	compress := func(newWriter func(io.Writer) io.WriteCloser) []byte {
+		var buf bytes.Buffer
+		zw := newWriter(&buf)
+		zw.Write([]byte(plain))
+		zw.Close()
+		return buf.Bytes()
+	}
+	gz := gzipBytes(t, plain)
+	tests := []struct {
+		name       string
+		encoding   string
+		body       []byte
+		wantStatus int
+	}{
+		{"none", "", []byte(plain), http.StatusOK},
+		{"identity", "identity", []byte(plain), http.StatusOK},
+		{"gzip", "gzip", gz, http.StatusOK},
+		{"x-gzip", "x-gzip", gz, http.StatusOK},
+		{"case and space", " GZIP ", gz, http.StatusOK},
+		{"deflate", "deflate", compress(func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }), http.StatusOK},
+		{"br", "br", compress(func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }), http.StatusOK},
+		{"invalid deflate", "deflate", []byte(plain), http.StatusBadRequest},
+		{"unsupported", "zstd", []byte(plain), http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, _ := observedLogger(zapcore.DebugLevel)
+			var got []byte
+			r := gin.New()
+			r.Use(DecompressRequest(logger, DecompressOptions{}))
+			r.POST("/x", func(c *gin.Context) {
+				got, _ = io.ReadAll(c.Request.Body)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/x", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			w := serve(r, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && string(got) != plain {
+				t.Errorf("handler read %q, want %q", got, plain)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorBlue   = "\033[34m"
+	colorCyan   = "\033[36m"
+	colorGray   = "\033[90m"
+)
+
+// DevLogger writes one compact colorized line per request to w (os.Stdout
+// when nil), for local development next to or instead of the JSON Logger.
+// Colors are dropped when NO_COLOR is set.
+func DevLogger(w io.Writer) gin.HandlerFunc {
+	if w == nil {
+		w = os.Stdout
+	}
+	color := os.Getenv("NO_COLOR") == ""
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + colorReset
+	}
+	var mu sync.Mutex
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		defer func() {
+			r := recover()
+			status := finalStatus(c, r)
+			path := c.Request.URL.Path
+			if c.Request.URL.RawQuery != "" {
+				path += "?" + c.Request.URL.RawQuery
+			}
+			line := fmt.Sprintf("%s %s %s %10s %s %s\n",
+				start.Format("15:04:05"),
+				paint(methodColor(c.Request.Method), fmt.Sprintf("%-7s", c.Request.Method)),
+				paint(statusColor(status), fmt.Sprintf("%3d", status)),
+				time.Since(start).Round(time.Microsecond),
+				path,
+				paint(colorGray, getRequestID(c)),
+			)
+			mu.Lock()
+			io.WriteString(w, line)
+			mu.Unlock()
+			if r != nil {
+				panic(r)
+			}
+		}()
+		c.Next()
+	}
+}
+
+func methodColor(method string) string {
+	switch method {
+	case "GET", "HEAD":
+		return colorBlue
+	case "POST":
+		return colorCyan
+	case "PUT", "PATCH":
+		return colorYellow
+	case "DELETE":
+		return colorRed
+	default:
+		return colorReset
+	}
+}
+
+func statusColor(status int) string {
+	switch {
+	case status >= 500:
+		return colorRed
+	case status >= 400:
+		return colorYellow
+	case status >= 300:
+		return colorCyan
+	default:
+		return colorGreen
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+)
+
+type envelope struct {
+	Data      json.RawMessage `json:"data"`
+	RequestID string          `json:"request_id"`
+}
+
+// Envelope wraps 2xx JSON responses as {"data": <body>, "request_id": "..."}.
+// Other responses pass through unchanged.
+func Envelope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := newBufferedResponseWriter(c.Writer)
+		nextBuffered(c, w)
+
+		body := w.body.Bytes()
+		if w.status >= 200 && w.status < 300 && isJSONContentType(w.Header().Get("Content-Type")) && json.Valid(body) {
+			if b, err := json.Marshal(envelope{Data: body, RequestID: getRequestID(c)}); err == nil {
+				w.body.Reset()
+				w.body.Write(b)
+				w.Header().Del("Content-Length")
+			}
+		}
+		w.flush()
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEnvelope(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantBody   string
+	}{
+		{"json ok", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"a": 1}) },
+			http.StatusOK, `{"data":{"a":1},"request_id":"xid-1"}`},
+		{"json created", func(c *gin.Context) { c.JSON(http.StatusCreated, []int{1, 2}) },
+			http.StatusCreated, `{"data":[1,2],"request_id":"xid-1"}`},
+		{"json error", func(c *gin.Context) { c.JSON(http.StatusBadRequest, gin.H{"a": 1}) },
+			http.StatusBadRequest, `{"a":1}`},
+		{"not json", func(c *gin.Context) { c.String(http.StatusOK, "plain") },
+			http.StatusOK, "plain"},
+		{"invalid json", func(c *gin.Context) { c.Data(http.StatusOK, gin.MIMEJSON, []byte("{")) },
+			http.StatusOK, "{"},
+		{"panic", func(c *gin.Context) { panic("boom") },
+			http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(gin.Recovery(), Envelope())
+			r.GET("/x", tt.handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			req.Header.Set(X_REQUEST_ID, "xid-1")
+			w := serve(r, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
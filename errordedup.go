@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	requestErrorMsg    = "request_error"
+	repeatedErrorMsg   = "request_error_repeated"
+	maxDedupedMessages = 1000
+)
+
+// WithErrorDedup makes Logger log errors attached with c.Error. The first
+// occurrence of a message is logged at once; repeats within window are folded
+// into one request_error_repeated line with their count and up to samples
+// request IDs.
+func WithErrorDedup(window time.Duration, samples int) Option {
+	d := newErrorDedup(window, samples, func(_ *gin.Context, e *gin.Error) string { return e.Error() }, false)
+	return func(o *options) {
+		o.errorDedup = d
+	}
+}
+
+// WithErrorFingerprint makes Logger log errors attached with c.Error, in full
+// (method, path, meta and %+v detail, which includes stacks for errors that
+// carry one) only for the first occurrence of each fingerprint per window;
+// later ones are counted into request_error_repeated. fingerprint defaults to
+// the error message plus route. It replaces WithErrorDedup.
+func WithErrorFingerprint(window time.Duration, fingerprint func(c *gin.Context, e *gin.Error) string) Option {
+	if fingerprint == nil {
+		fingerprint = func(c *gin.Context, e *gin.Error) string { return e.Error() + " " + c.FullPath() }
+	}
+	d := newErrorDedup(window, 0, fingerprint, true)
+	return func(o *options) {
+		o.errorDedup = d
+	}
+}
+
+type errorDedup struct {
+	window  time.Duration
+	samples int
+	key     func(c *gin.Context, e *gin.Error) string
+	full    bool
+
+	mu   sync.Mutex
+	seen map[string]*dedupEntry
+}
+
+type dedupEntry struct {
+	msg   string
+	count int
+	xids  []string
+}
+
+func newErrorDedup(window time.Duration, samples int, key func(*gin.Context, *gin.Error) string, full bool) *errorDedup {
+	return &errorDedup{window: window, samples: samples, key: key, full: full, seen: map[string]*dedupEntry{}}
+}
+
+func (d *errorDedup) log(logger *zap.Logger, c *gin.Context) {
+	xid := getRequestID(c)
+	for _, e := range c.Errors {
+		key := d.key(c, e)
+		d.mu.Lock()
+		entry, repeated := d.seen[key]
+		if repeated {
+			entry.count++
+			if len(entry.xids) < d.samples {
+				entry.xids = append(entry.xids, xid)
+			}
+		} else if len(d.seen) < maxDedupedMessages {
+			d.seen[key] = &dedupEntry{msg: e.Error()}
+			time.AfterFunc(d.window, func() { d.flush(logger, key) })
+		}
+		d.mu.Unlock()
+
+		if repeated {
+			continue
+		}
+		zf := []zap.Field{zap.String("xid", xid), zap.String("error", e.Error())}
+		if d.full {
+			zf = append(zf,
+				zap.String("method", c.Request.Method),
+				zap.String("path_uri", routePath(c)),
+				zap.String("error_detail", fmt.Sprintf("%+v", e.Err)),
+				zap.Any("meta", e.Meta),
+			)
+		}
+		logger.Error(requestErrorMsg, zf...)
+	}
+}
+
+func (d *errorDedup) flush(logger *zap.Logger, key string) {
+	d.mu.Lock()
+	entry := d.seen[key]
+	delete(d.seen, key)
+	d.mu.Unlock()
+	if entry.count > 0 {
+		zf := []zap.Field{
+			zap.String("error", entry.msg),
+			zap.Int("count", entry.count),
+			zap.String("window", d.window.String()),
+		}
+		if d.samples > 0 {
+			zf = append(zf, zap.Strings("sample_xids", entry.xids))
+		}
+		logger.Error(repeatedErrorMsg, zf...)
+	}
+}
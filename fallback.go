@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fallbackKeys are the fields kept in a fallback line, when present.
+var fallbackKeys = []string{"xid", "method", "path_uri", "status", "latency"}
+
+// WithFallback wraps a logger's core so that any entry the core fails to
+// write, say on a full disk or broken pipe, is written to w (os.Stderr when
+// nil) as one plain line with the time, level, message, write error and the
+// request fields that identify it. Use it on the logger handed to Logger:
+//
+//	logger = logger.WithOptions(middleware.WithFallback(nil))
+func WithFallback(w io.Writer) zap.Option {
+	if w == nil {
+		w = os.Stderr
+	}
+	mu := &sync.Mutex{}
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &fallbackCore{Core: core, w: w, mu: mu}
+	})
+}
+
+type fallbackCore struct {
+	zapcore.Core
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+func (c *fallbackCore) With(fields []zapcore.Field) zapcore.Core {
+	return &fallbackCore{Core: c.Core.With(fields), w: c.w, mu: c.mu}
+}
+
+func (c *fallbackCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	// Let the wrapped core pick the cores that take ent (levels, sampling,
+	// tee branches) and write through exactly those.
+	inner := c.Core.Check(ent, nil)
+	if inner == nil {
+		return ce
+	}
+	return ce.AddCore(ent, &fallbackWrite{fallback: c, inner: inner})
+}
+
+func (c *fallbackCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+	if err != nil {
+		c.writeFallback(ent, fields, err)
+	}
+	return err
+}
+
+func (c *fallbackCore) writeFallback(ent zapcore.Entry, fields []zapcore.Field, err error) {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s write_error=%q", ent.Time.Format(time.RFC3339Nano), ent.Level.CapitalString(), ent.Message, err.Error())
+	for _, k := range fallbackKeys {
+		if v, ok := enc.Fields[k]; ok {
+			fmt.Fprintf(&b, " %s=%v", k, v)
+		}
+	}
+	b.WriteByte('\n')
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	io.WriteString(c.w, b.String())
+}
+
+// fallbackWrite writes one checked entry through the cores the wrapped core
+// chose for it. CheckedEntry only reports write errors to its ErrorOutput, so
+// that is where they are caught.
+type fallbackWrite struct {
+	fallback *fallbackCore
+	inner    *zapcore.CheckedEntry
+}
+
+func (w *fallbackWrite) Enabled(zapcore.Level) bool        { return true }
+func (w *fallbackWrite) With([]zapcore.Field) zapcore.Core { return w }
+func (w *fallbackWrite) Sync() error                       { return nil }
+func (w *fallbackWrite) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce
+}
+
+func (w *fallbackWrite) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var errs writeErrors
+	w.inner.ErrorOutput = &errs
+	w.inner.Write(fields...)
+	if errs.Len() == 0 {
+		return nil
+	}
+	msg := strings.TrimSpace(errs.String())
+	if _, after, ok := strings.Cut(msg, "write error: "); ok {
+		msg = after
+	}
+	err := errors.New(msg)
+	w.fallback.writeFallback(ent, fields, err)
+	return err
+}
+
+type writeErrors struct {
+	strings.Builder
+}
+
+func (w *writeErrors) Sync() error {
+	return nil
+}
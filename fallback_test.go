@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWithFallback(t *testing.T) {
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	tests := []struct {
+		name         string
+		failing      bool
+		level        zapcore.Level
+		wantFallback bool
+		wantObserved bool // the Error-only tee branch got the entry
+	}{
+		{"healthy", false, zapcore.InfoLevel, false, false},
+		{"failing info", true, zapcore.InfoLevel, true, false},
+		{"failing error", true, zapcore.ErrorLevel, true, true},
+		{"failing debug, below level", true, zapcore.DebugLevel, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, fallback bytes.Buffer
+			var sink zapcore.WriteSyncer = zapcore.AddSync(&out)
+			if tt.failing {
+				sink = zapcore.AddSync(failingWriter{})
+			}
+			observed, logs := observer.New(zapcore.ErrorLevel)
+			core := zapcore.NewTee(zapcore.NewCore(enc, sink, zapcore.InfoLevel), observed)
+			logger := zap.New(core, WithFallback(&fallback))
+
+			if ce := logger.Check(tt.level, "api_summary"); ce != nil {
+				ce.Write(zap.String("xid", "x1"), zap.Int("status", 500), zap.String("body", "secret"))
+			}
+
+			line := fallback.String()
+			if got := line != ""; got != tt.wantFallback {
+				t.Fatalf("fallback = %q, want written: %v", line, tt.wantFallback)
+			}
+			if got := logs.Len() == 1; got != tt.wantObserved {
+				t.Errorf("observed %d entries, want observed: %v", logs.Len(), tt.wantObserved)
+			}
+			if !tt.wantFallback {
+				return
+			}
+			for _, want := range []string{tt.level.CapitalString(), "api_summary", `write_error="disk full"`, "xid=x1", "status=500"} {
+				if !strings.Contains(line, want) {
+					t.Errorf("fallback %q is missing %q", line, want)
+				}
+			}
+			if strings.Contains(line, "secret") {
+				t.Errorf("fallback %q carries a field outside fallbackKeys", line)
+			}
+		})
+	}
+}
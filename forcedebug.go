@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WithForceDebugWhen makes RequestLogger and ResponseLogger log full bodies for
+// requests matching fn even when the logger runs at Info. fn is the security
+// boundary: never enable it off a header any client can set, see
+// DebugHeaderWithToken.
+func WithForceDebugWhen(fn func(c *gin.Context) bool) Option {
+	return func(o *options) {
+		o.forceDebugWhen = fn
+	}
+}
+
+// DebugHeaderWithToken returns a predicate for WithForceDebugWhen that matches
+// requests whose header carries the shared secret token.
+func DebugHeaderWithToken(header, token string) func(c *gin.Context) bool {
+	return func(c *gin.Context) bool {
+		v := c.GetHeader(header)
+		return token != "" && subtle.ConstantTimeCompare([]byte(v), []byte(token)) == 1
+	}
+}
+
+func (o *options) forceDebug(c *gin.Context) bool {
+	return o.forceDebugWhen != nil && o.forceDebugWhen(c)
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const staleRequestMsg = "stale_request"
+
+type FreshnessOptions struct {
+	// Header defaults to "Date". Unix seconds, RFC 3339 and HTTP dates are
+	// accepted.
+	Header string
+	// MaxSkew defaults to five minutes.
+	MaxSkew time.Duration
+	// RejectFuture also rejects timestamps more than MaxSkew ahead.
+	RejectFuture bool
+}
+
+// Freshness rejects with 401 requests whose timestamp header is missing,
+// malformed or older than MaxSkew. Pair it with VerifySignature, signing the
+// timestamp, for replay protection.
+func Freshness(logger *zap.Logger, opts FreshnessOptions) gin.HandlerFunc {
+	if opts.Header == "" {
+		opts.Header = "Date"
+	}
+	if opts.MaxSkew <= 0 {
+		opts.MaxSkew = 5 * time.Minute
+	}
+	return func(c *gin.Context) {
+		raw := c.GetHeader(opts.Header)
+		ts, ok := parseTimestamp(raw)
+		now := time.Now()
+		if ok && now.Sub(ts) <= opts.MaxSkew && (!opts.RejectFuture || ts.Sub(now) <= opts.MaxSkew) {
+			c.Next()
+			return
+		}
+
+		logger.Warn(staleRequestMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("path_uri", routePath(c)),
+			zap.String("request_time", raw),
+			zap.Time("server_time", now),
+		)
+		abortWithError(c, http.StatusUnauthorized, "request timestamp outside allowed window")
+	}
+}
+
+func parseTimestamp(v string) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, false
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0), true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
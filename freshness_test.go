@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFreshness(t *testing.T) {
+	now := time.Now()
+	unix := func(d time.Duration) string { return strconv.FormatInt(now.Add(d).Unix(), 10) }
+	tests := []struct {
+		name   string
+		opts   FreshnessOptions
+		header string // the Date header unless opts.Header is set
+		value  string
+		want   int
+	}{
+		{"unix seconds", FreshnessOptions{}, "", unix(-time.Minute), http.StatusOK},
+		{"rfc 3339", FreshnessOptions{}, "", now.Add(-time.Minute).Format(time.RFC3339), http.StatusOK},
+		{"http date", FreshnessOptions{}, "", now.Add(-time.Minute).UTC().Format(http.TimeFormat), http.StatusOK},
+		{"custom header", FreshnessOptions{Header: "X-Timestamp"}, "X-Timestamp", unix(0), http.StatusOK},
+		{"future allowed", FreshnessOptions{}, "", unix(time.Hour), http.StatusOK},
+		{"stale", FreshnessOptions{}, "", unix(-6 * time.Minute), http.StatusUnauthorized},
+		{"stale http date", FreshnessOptions{}, "", now.Add(-time.Hour).UTC().Format(http.TimeFormat), http.StatusUnauthorized},
+		{"stale past custom skew", FreshnessOptions{MaxSkew: 10 * time.Second}, "", unix(-time.Minute), http.StatusUnauthorized},
+		{"future rejected", FreshnessOptions{RejectFuture: true}, "", unix(time.Hour), http.StatusUnauthorized},
+		{"future within skew", FreshnessOptions{RejectFuture: true}, "", unix(time.Minute), http.StatusOK},
+		{"missing", FreshnessOptions{}, "", "", http.StatusUnauthorized},
+		{"malformed", FreshnessOptions{}, "", "yesterday", http.StatusUnauthorized},
+		{"wrong header", FreshnessOptions{Header: "X-Timestamp"}, "", unix(0), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.WarnLevel)
+			reached := false
+			r := gin.New()
+			r.Use(Freshness(logger, tt.opts))
+			r.GET("/x", func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			header := tt.header
+			if header == "" {
+				header = "Date"
+			}
+			if tt.value != "" {
+				req.Header.Set(header, tt.value)
+			}
+			w := serve(r, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if reached != (tt.want == http.StatusOK) {
+				t.Errorf("handler reached = %v", reached)
+			}
+			entries := logs.FilterMessage(staleRequestMsg).All()
+			if tt.want == http.StatusOK {
+				if len(entries) != 0 {
+					t.Errorf("logs = %v, want none", summaries(logs))
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("logs = %v, want one %s", summaries(logs), staleRequestMsg)
+			}
+			want := tt.value
+			if tt.opts.Header != "" && tt.opts.Header != header {
+				want = ""
+			}
+			if got := entries[0].ContextMap()["request_time"]; got != want {
+				t.Errorf("request_time = %v, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerGCPFields(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       string
+		status       int
+		wantSeverity string
+		wantURL      string
+	}{
+		{"ok", "/x", http.StatusOK, "INFO", "/x"},
+		{"client error", "/x", http.StatusNotFound, "WARNING", "/x"},
+		{"server error", "/x", http.StatusBadGateway, "ERROR", "/x"},
+		{"redacted query", "/x?token=abc&page=2", http.StatusOK, "INFO", "/x?token=REDACTED&page=2"},
+		{"encoded key redacted", "/x?%74oken=abc", http.StatusOK, "INFO", "/x?%74oken=REDACTED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			r := gin.New()
+			r.Use(Logger(logger, WithGCPFields(), WithOutcomeClassifier(StatusOutcome), WithRedactQuery("token")))
+			r.GET("/x", func(c *gin.Context) { c.String(tt.status, "body") })
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("User-Agent", "test-agent")
+			serve(r, req)
+
+			got := summaries(logs)
+			if len(got) != 1 {
+				t.Fatalf("got %d api summaries, want 1", len(got))
+			}
+			fields := got[0].ContextMap()
+			if severity := fields["severity"]; severity != tt.wantSeverity {
+				t.Errorf("severity = %v, want %s", severity, tt.wantSeverity)
+			}
+			httpRequest, _ := fields["httpRequest"].(map[string]interface{})
+			want := map[string]interface{}{
+				"requestMethod": http.MethodGet,
+				"requestUrl":    tt.wantURL,
+				"status":        tt.status,
+				"responseSize":  "4",
+				"userAgent":     "test-agent",
+				"protocol":      "HTTP/1.1",
+			}
+			for k, v := range want {
+				if httpRequest[k] != v {
+					t.Errorf("httpRequest.%s = %v, want %v", k, httpRequest[k], v)
+				}
+			}
+		})
+	}
+}
@@ -3,9 +3,14 @@ module github.com/bc-infinitaskt/middleware
 go 1.20
 
 require (
+	github.com/andybalholm/brotli v1.0.5
 	github.com/gin-gonic/gin v1.9.1
 	github.com/google/uuid v1.3.0
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/metric v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
 	go.uber.org/zap v1.24.0
+	golang.org/x/sync v0.3.0
 )
 
 require (
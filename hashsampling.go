@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"hash/fnv"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WithLogSampleRate makes Logger write the api summary for only the given
+// fraction (0..1) of requests, picked by hashing the request ID so that a
+// request is either logged by every replica and restart or by none, which
+// keeps sampled logs joinable with other signals keyed on it. 5xx responses
+// and requests without an ID are always logged.
+func WithLogSampleRate(rate float64) Option {
+	return func(o *options) {
+		o.logSampleRate = rate
+		o.logSampling = true
+	}
+}
+
+func (o *options) logSampled(c *gin.Context, status int) bool {
+	if !o.logSampling || status >= http.StatusInternalServerError {
+		return true
+	}
+	xid := getRequestID(c)
+	if xid == "" {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write([]byte(xid))
+	return float64(h.Sum64())/math.MaxUint64 < o.logSampleRate
+}
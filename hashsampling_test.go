@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerLogSampleRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		rate   float64
+		xid    string
+		status int
+		want   bool
+	}{
+		{"rate 1", 1, "abc", http.StatusOK, true},
+		{"rate 0", 0, "abc", http.StatusOK, false},
+		{"rate 0, client error", 0, "abc", http.StatusNotFound, false},
+		{"rate 0, server error", 0, "abc", http.StatusBadGateway, true},
+		{"rate 0, no request id", 0, "", http.StatusOK, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			r := gin.New()
+			r.Use(Logger(logger, WithLogSampleRate(tt.rate)))
+			r.GET("/x", func(c *gin.Context) { c.Status(tt.status) })
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			if tt.xid != "" {
+				req.Header.Set(X_REQUEST_ID, tt.xid)
+			}
+			serve(r, req)
+
+			if got := len(summaries(logs)) == 1; got != tt.want {
+				t.Errorf("logged = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Every replica must make the same decision for a request ID.
+func TestLoggerLogSampleRateDeterministic(t *testing.T) {
+	replica := func() (*gin.Engine, func() int) {
+		logger, logs := observedLogger(zapcore.DebugLevel)
+		r := gin.New()
+		r.Use(Logger(logger, WithLogSampleRate(0.5)))
+		r.GET("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+		return r, func() int { return len(summaries(logs)) }
+	}
+	a, loggedA := replica()
+	b, loggedB := replica()
+
+	sampled := 0
+	for i := 0; i < 200; i++ {
+		xid := "req-" + strconv.Itoa(i)
+		for _, r := range []*gin.Engine{a, b} {
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			req.Header.Set(X_REQUEST_ID, xid)
+			serve(r, req)
+		}
+		if loggedA() != loggedB() {
+			t.Fatalf("replicas disagree on request ID %q", xid)
+		}
+		sampled = loggedA()
+	}
+	if sampled == 0 || sampled == 200 {
+		t.Errorf("sampled %d of 200 at rate 0.5", sampled)
+	}
+}
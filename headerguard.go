@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	headerInjectionMsg    = "header_injection"
+	defaultForbiddenChars = "\r\n\x00"
+)
+
+type HeaderGuardOptions struct {
+	// Chars are the characters no header value may contain; defaults to CR,
+	// LF and NUL.
+	Chars string
+	// Sanitize strips the characters instead of aborting with 400.
+	Sanitize bool
+}
+
+// HeaderGuard rejects requests whose header values contain control
+// characters used for header or log splitting. Only the offending header
+// names are logged, never their values.
+func HeaderGuard(logger *zap.Logger, opts HeaderGuardOptions) gin.HandlerFunc {
+	if opts.Chars == "" {
+		opts.Chars = defaultForbiddenChars
+	}
+	strip := func(r rune) rune {
+		if strings.ContainsRune(opts.Chars, r) {
+			return -1
+		}
+		return r
+	}
+	return func(c *gin.Context) {
+		var bad []string
+		for name, values := range c.Request.Header {
+			for _, v := range values {
+				if strings.ContainsAny(v, opts.Chars) {
+					bad = append(bad, name)
+					break
+				}
+			}
+		}
+		if bad == nil {
+			c.Next()
+			return
+		}
+
+		sort.Strings(bad)
+		logger.Warn(headerInjectionMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("method", c.Request.Method),
+			zap.String("path_uri", routePath(c)),
+			zap.Strings("headers", bad),
+			zap.Bool("sanitized", opts.Sanitize),
+		)
+		if !opts.Sanitize {
+			abortWithError(c, http.StatusBadRequest, "invalid characters in request headers")
+			return
+		}
+		for _, name := range bad {
+			values := c.Request.Header[name]
+			for i, v := range values {
+				values[i] = strings.Map(strip, v)
+			}
+		}
+		c.Next()
+	}
+}
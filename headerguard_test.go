@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestHeaderGuard(t *testing.T) {
+	large := strings.Repeat("a", 64<<10)
+	tests := []struct {
+		name        string
+		opts        HeaderGuardOptions
+		header      http.Header
+		want        int
+		wantBad     []string
+		wantHeaders http.Header // as the handler sees them
+	}{
+		{"clean", HeaderGuardOptions{}, http.Header{"X-A": {"v"}}, http.StatusOK, nil, http.Header{"X-A": {"v"}}},
+		{"large clean value", HeaderGuardOptions{}, http.Header{"X-A": {large}}, http.StatusOK, nil, http.Header{"X-A": {large}}},
+		{"tab allowed", HeaderGuardOptions{}, http.Header{"X-A": {"a\tb"}}, http.StatusOK, nil, http.Header{"X-A": {"a\tb"}}},
+		{"cr", HeaderGuardOptions{}, http.Header{"X-A": {"a\rb"}}, http.StatusBadRequest, []string{"X-A"}, nil},
+		{"lf", HeaderGuardOptions{}, http.Header{"X-A": {"a\nSet-Cookie: x=1"}}, http.StatusBadRequest, []string{"X-A"}, nil},
+		{"nul", HeaderGuardOptions{}, http.Header{"X-A": {"a\x00"}}, http.StatusBadRequest, []string{"X-A"}, nil},
+		{"end of large value", HeaderGuardOptions{}, http.Header{"X-A": {large + "\r\n"}}, http.StatusBadRequest, []string{"X-A"}, nil},
+		{"second value", HeaderGuardOptions{}, http.Header{"X-A": {"ok", "a\nb"}}, http.StatusBadRequest, []string{"X-A"}, nil},
+		{"several headers", HeaderGuardOptions{}, http.Header{"X-B": {"\n"}, "X-A": {"\r"}, "X-C": {"ok"}}, http.StatusBadRequest, []string{"X-A", "X-B"}, nil},
+		{"custom chars", HeaderGuardOptions{Chars: "<>"}, http.Header{"X-A": {"<script>"}}, http.StatusBadRequest, []string{"X-A"}, nil},
+		{"custom chars replace defaults", HeaderGuardOptions{Chars: "<>"}, http.Header{"X-A": {"a\rb"}}, http.StatusOK, nil, http.Header{"X-A": {"a\rb"}}},
+		{"sanitize", HeaderGuardOptions{Sanitize: true}, http.Header{"X-A": {"a\r\nb", "c\x00"}, "X-B": {"ok"}}, http.StatusOK, []string{"X-A"}, http.Header{"X-A": {"ab", "c"}, "X-B": {"ok"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.WarnLevel)
+			var got http.Header
+			r := gin.New()
+			r.Use(HeaderGuard(logger, tt.opts))
+			r.GET("/x", func(c *gin.Context) {
+				got = c.Request.Header.Clone()
+				c.Status(http.StatusOK)
+			})
+
+			// Set directly: net/http refuses to build such requests itself.
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			req.Header = tt.header
+			w := serve(r, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.wantHeaders == nil {
+				if got != nil {
+					t.Errorf("handler reached with %v", got)
+				}
+			} else {
+				for k, want := range tt.wantHeaders {
+					if g := got[k]; strings.Join(g, "|") != strings.Join(want, "|") {
+						t.Errorf("%s = %q, want %q", k, g, want)
+					}
+				}
+			}
+			entries := logs.FilterMessage(headerInjectionMsg).All()
+			if tt.wantBad == nil {
+				if len(entries) != 0 {
+					t.Errorf("logs = %v, want none", summaries(logs))
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("logs = %v, want one %s", summaries(logs), headerInjectionMsg)
+			}
+			fields := entries[0].ContextMap()
+			bad, _ := fields["headers"].([]interface{})
+			if len(bad) != len(tt.wantBad) {
+				t.Fatalf("headers = %v, want %v", fields["headers"], tt.wantBad)
+			}
+			for i, name := range tt.wantBad {
+				if bad[i] != name {
+					t.Errorf("headers[%d] = %v, want %s", i, bad[i], name)
+				}
+			}
+			if fields["sanitized"] != tt.opts.Sanitize {
+				t.Errorf("sanitized = %v, want %v", fields["sanitized"], tt.opts.Sanitize)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const hookPanicMsg = "hook_panic"
+
+// WithBeforeRequest runs fn in Logger before the rest of the chain.
+func WithBeforeRequest(fn func(c *gin.Context)) Option {
+	return func(o *options) {
+		o.beforeRequest = fn
+	}
+}
+
+// WithAfterRequest runs fn in Logger after the api summary is written.
+func WithAfterRequest(fn func(c *gin.Context, latency time.Duration, status int)) Option {
+	return func(o *options) {
+		o.afterRequest = fn
+	}
+}
+
+// WithErrorCallback makes Logger call fn for every 5xx response, e.g. to
+// alert or bump a metric. fn runs on its own goroutine with a c.Copy(), so it
+// never delays the response; avoid anything but reads on it.
+func WithErrorCallback(fn func(c *gin.Context, status int)) Option {
+	return func(o *options) {
+		o.errorCallback = fn
+	}
+}
+
+// runHook calls fn, logging instead of propagating any panic.
+func runHook(logger *zap.Logger, c *gin.Context, name string, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error(hookPanicMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("hook", name),
+				zap.String("panic", fmt.Sprint(r)),
+			)
+		}
+	}()
+	fn()
+}
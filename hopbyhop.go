@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const hopByHopMsg = "hop_by_hop_stripped"
+
+// hopByHopHeaders are defined by RFC 7230 section 6.1, plus the common
+// non-standard Proxy-Connection.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// StripHopByHop removes hop-by-hop request headers, and any header named in
+// Connection, before the handler sees them. WebSocket upgrades keep Upgrade
+// and Connection. Stripped header names are logged at debug.
+func StripHopByHop(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h := c.Request.Header
+		upgrade := isWebSocketUpgrade(c)
+		var stripped []string
+		del := func(name string) {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if upgrade && (name == "Connection" || name == "Upgrade") {
+				return
+			}
+			if _, ok := h[name]; ok {
+				h.Del(name)
+				stripped = append(stripped, name)
+			}
+		}
+
+		for _, v := range h.Values("Connection") {
+			for _, name := range strings.Split(v, ",") {
+				del(name)
+			}
+		}
+		for _, name := range hopByHopHeaders {
+			del(name)
+		}
+
+		if len(stripped) > 0 {
+			logger.Debug(hopByHopMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("path_uri", routePath(c)),
+				zap.Strings("headers", stripped),
+			)
+		}
+		c.Next()
+	}
+}
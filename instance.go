@@ -0,0 +1,14 @@
+package middleware
+
+import "os"
+
+// WithInstance adds a static instance field to the api summary. An empty id
+// uses os.Hostname(), resolved once here rather than per request.
+func WithInstance(id string) Option {
+	if id == "" {
+		id, _ = os.Hostname()
+	}
+	return func(o *options) {
+		o.instance = id
+	}
+}
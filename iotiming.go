@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// minIOTime is the least read or write time worth logging.
+const minIOTime = time.Millisecond
+
+// WithIOTiming makes Logger log read_ms, the time spent reading the request
+// body, and write_ms, the time spent writing the response body, to tell slow
+// clients apart from slow handlers. Either is omitted below a millisecond.
+func WithIOTiming() Option {
+	return func(o *options) {
+		o.ioTiming = true
+	}
+}
+
+type ioTiming struct {
+	body *timedBody
+	w    *responseBodyWriter
+}
+
+func startIOTiming(c *gin.Context) *ioTiming {
+	t := &ioTiming{w: &responseBodyWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}, skipped: true}}
+	// Leave nil and http.NoBody alone; proxies and GetBody compare against them.
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		t.body = &timedBody{ReadCloser: c.Request.Body}
+		c.Request.Body = t.body
+	}
+	c.Writer = t.w
+	return t
+}
+
+func (t *ioTiming) fields() []zap.Field {
+	if t == nil {
+		return nil
+	}
+	var zf []zap.Field
+	if t.body != nil && t.body.d >= minIOTime {
+		zf = append(zf, zap.Float64("read_ms", float64(t.body.d.Microseconds())/1000))
+	}
+	if t.w.writeTime >= minIOTime {
+		zf = append(zf, zap.Float64("write_ms", float64(t.w.writeTime.Microseconds())/1000))
+	}
+	return zf
+}
+
+type timedBody struct {
+	io.ReadCloser
+	d time.Duration
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	start := time.Now()
+	n, err := b.ReadCloser.Read(p)
+	b.d += time.Since(start)
+	return n, err
+}
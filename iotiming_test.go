@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestIOTimingBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        io.ReadCloser
+		wantWrapped bool
+		wantRead    string
+	}{
+		{"nil body", nil, false, ""},
+		{"no body", http.NoBody, false, ""},
+		{"body", io.NopCloser(strings.NewReader("payload")), true, "payload"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				body io.ReadCloser
+				read string
+			)
+			r := gin.New()
+			r.Use(Logger(zap.NewNop(), WithIOTiming()))
+			r.POST("/x", func(c *gin.Context) {
+				body = c.Request.Body
+				if body != nil {
+					b, _ := io.ReadAll(body)
+					read = string(b)
+				}
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/x", nil)
+			req.Body = tt.body
+			serve(r, req)
+
+			if _, wrapped := body.(*timedBody); wrapped != tt.wantWrapped {
+				t.Errorf("body wrapped = %v, want %v", wrapped, tt.wantWrapped)
+			}
+			if !tt.wantWrapped && body != tt.body {
+				t.Errorf("body = %#v, want it left as %#v", body, tt.body)
+			}
+			if read != tt.wantRead {
+				t.Errorf("handler read %q, want %q", read, tt.wantRead)
+			}
+		})
+	}
+}
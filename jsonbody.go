@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WithJSONBodyValidation makes RequestLogger reject JSON request bodies that
+// are not valid UTF-8 JSON with a 400 naming the error offset, before any
+// handler runs. Empty bodies are left to the handler.
+func WithJSONBodyValidation() Option {
+	return func(o *options) {
+		o.validateJSONBody = true
+	}
+}
+
+// jsonBodyError describes why body is not valid JSON, or returns "".
+func jsonBodyError(body []byte) string {
+	if !utf8.Valid(body) {
+		return "request body is not valid UTF-8"
+	}
+	var v interface{}
+	err := json.Unmarshal(body, &v)
+	if err == nil {
+		return ""
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Sprintf("invalid JSON at offset %d: %s", syntaxErr.Offset, syntaxErr.Error())
+	}
+	return "invalid JSON: " + err.Error()
+}
+
+func (o *options) checkJSONBody(c *gin.Context, body []byte) bool {
+	if len(body) == 0 || !isJSONContentType(c.GetHeader("Content-Type")) {
+		return true
+	}
+	if msg := jsonBodyError(body); msg != "" {
+		abortWithError(c, http.StatusBadRequest, msg)
+		return false
+	}
+	return true
+}
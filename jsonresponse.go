@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WithValidateJSON makes ResponseLogger warn about JSON responses that do not
+// parse, and about JSON-looking bodies sent without a JSON content type.
+func WithValidateJSON() Option {
+	return func(o *options) {
+		o.validateJSON = true
+	}
+}
+
+func (o *options) validateJSONResponse(logger *zap.Logger, c *gin.Context, body []byte) {
+	contentType := c.Writer.Header().Get("Content-Type")
+	if isJSONContentType(contentType) {
+		if len(body) > 0 && !json.Valid(body) {
+			logger.Warn(responseInfoMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("path_uri", o.pathOf(c)),
+				zap.Bool("invalid_json_response", true),
+			)
+		}
+		return
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		logger.Warn(responseInfoMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("path_uri", o.pathOf(c)),
+			zap.String("content_type", contentType),
+			zap.Bool("missing_json_content_type", true),
+		)
+	}
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+var testJWTSecret = []byte("secret")
+
+// signJWT returns a token claiming alg in its header but always signed with
+// HMAC-SHA256, so any other alg must fail verification.
+func signJWT(t *testing.T, alg string, secret []byte, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestHMACVerifier(t *testing.T) {
+	future := float64(time.Now().Add(time.Hour).Unix())
+	past := float64(time.Now().Add(-time.Hour).Unix())
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid", signJWT(t, "HS256", testJWTSecret, map[string]interface{}{"sub": "u1"}), false},
+		{"not expired", signJWT(t, "HS256", testJWTSecret, map[string]interface{}{"sub": "u1", "exp": future}), false},
+		{"expired", signJWT(t, "HS256", testJWTSecret, map[string]interface{}{"sub": "u1", "exp": past}), true},
+		{"wrong secret", signJWT(t, "HS256", []byte("other"), map[string]interface{}{"sub": "u1"}), true},
+		{"alg none", signJWT(t, "none", testJWTSecret, map[string]interface{}{"sub": "u1"}), true},
+		{"alg mismatch", signJWT(t, "HS512", testJWTSecret, map[string]interface{}{"sub": "u1"}), true},
+		{"malformed", "a.b", true},
+		{"bad header", "!!.e30.sig", true},
+	}
+	verify := HMACVerifier(testJWTSecret)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verify(tt.token); (err != nil) != tt.wantErr {
+				t.Errorf("verify = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWTSubject(t *testing.T) {
+	valid := signJWT(t, "HS256", testJWTSecret, map[string]interface{}{"sub": "u1", "org": "acme"})
+	forged := signJWT(t, "HS256", []byte("other"), map[string]interface{}{"sub": "u1"})
+	tests := []struct {
+		name         string
+		auth         string
+		verify       func(string) error
+		wantUser     string
+		wantVerified bool
+		wantOrg      interface{}
+	}{
+		{"no header", "", HMACVerifier(testJWTSecret), "", false, nil},
+		{"not bearer", "Basic dTpw", HMACVerifier(testJWTSecret), "", false, nil},
+		{"verified", "Bearer " + valid, HMACVerifier(testJWTSecret), "u1", true, "acme"},
+		{"failed verify", "Bearer " + forged, HMACVerifier(testJWTSecret), "", false, nil},
+		{"decode only", "Bearer " + forged, nil, "u1", false, nil},
+		{"undecodable", "Bearer not-a-jwt", nil, "", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			r := gin.New()
+			r.Use(Logger(logger), JWTSubject(JWTOptions{Claims: []string{"org"}, Verify: tt.verify}))
+			r.GET("/x", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			if w := serve(r, req); w.Code != http.StatusNoContent {
+				t.Fatalf("status = %d, JWTSubject must not reject", w.Code)
+			}
+
+			got := summaries(logs)
+			if len(got) != 1 {
+				t.Fatalf("got %d api summaries, want 1", len(got))
+			}
+			fields := got[0].ContextMap()
+			if user, _ := fields["user_id"].(string); user != tt.wantUser {
+				t.Errorf("user_id = %q, want %q", user, tt.wantUser)
+			}
+			if verified, _ := fields["user_verified"].(bool); verified != tt.wantVerified {
+				t.Errorf("user_verified = %v, want %v", verified, tt.wantVerified)
+			}
+			if org := fields["jwt.org"]; org != tt.wantOrg {
+				t.Errorf("jwt.org = %v, want %v", org, tt.wantOrg)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type LatencyFormat int
+
+const (
+	// DurationString logs latency as a duration string such as "1.2s".
+	DurationString LatencyFormat = iota
+	// Seconds logs latency as float seconds.
+	Seconds
+	// StartEnd logs RFC3339Nano start and end timestamps instead of latency.
+	StartEnd
+)
+
+func WithLatencyFormat(f LatencyFormat) Option {
+	return func(o *options) {
+		o.latencyFormat = f
+	}
+}
+
+func (o *options) latencyFields(start, end time.Time) []zap.Field {
+	switch o.latencyFormat {
+	case Seconds:
+		return []zap.Field{zap.Float64("latency", end.Sub(start).Seconds())}
+	case StartEnd:
+		return []zap.Field{
+			zap.String("start", start.Format(time.RFC3339Nano)),
+			zap.String("end", end.Format(time.RFC3339Nano)),
+		}
+	default:
+		return []zap.Field{zap.String("latency", end.Sub(start).String())}
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const localeKey = "locale"
+
+// Locale resolves the best supported locale from Accept-Language and stores
+// it on the context. The first supported locale is the fallback.
+func Locale(supported ...string) gin.HandlerFunc {
+	fallback := ""
+	if len(supported) > 0 {
+		fallback = supported[0]
+	}
+	return func(c *gin.Context) {
+		locale := matchLocale(c.GetHeader("Accept-Language"), supported)
+		if locale == "" {
+			locale = fallback
+		}
+		c.Set(localeKey, locale)
+		c.Next()
+	}
+}
+
+func LocaleFromContext(c *gin.Context) string {
+	return c.GetString(localeKey)
+}
+
+type weightedTag struct {
+	tag string
+	q   float64
+}
+
+func parseAcceptLanguage(header string) []weightedTag {
+	var tags []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f < 0 || f > 1 {
+				continue
+			}
+			q = f
+		}
+		if q == 0 {
+			continue
+		}
+		tags = append(tags, weightedTag{tag: tag, q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	return tags
+}
+
+func matchLocale(header string, supported []string) string {
+	for _, t := range parseAcceptLanguage(header) {
+		if t.tag == "*" {
+			return ""
+		}
+		for _, s := range supported {
+			if strings.EqualFold(t.tag, s) {
+				return s
+			}
+		}
+		base, _, _ := strings.Cut(t.tag, "-")
+		for _, s := range supported {
+			sb, _, _ := strings.Cut(s, "-")
+			if strings.EqualFold(base, sb) {
+				return s
+			}
+		}
+	}
+	return ""
+}
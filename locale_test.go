@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"no header", "", "en"},
+		{"exact match", "fr", "fr"},
+		{"case insensitive", "FR", "fr"},
+		{"base language", "fr-CA", "fr"},
+		{"regional supported", "pt-BR", "pt-BR"},
+		{"region of regional", "pt-PT", "pt-BR"},
+		{"highest q wins", "de;q=0.5, fr;q=0.9", "fr"},
+		{"order breaks ties", "fr, de", "fr"},
+		{"unsupported falls back", "ja, zh", "en"},
+		{"skips unsupported", "ja, de;q=0.1", "de"},
+		{"q=0 excluded", "fr;q=0, de;q=0.2", "de"},
+		{"invalid q skipped", "fr;q=2, de;q=0.1", "de"},
+		{"malformed q skipped", "fr;q=x, de", "de"},
+		{"wildcard falls back", "*", "en"},
+		{"wildcard after match", "fr, *;q=0.1", "fr"},
+		{"empty members", ", ,fr", "fr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			r := gin.New()
+			r.Use(Locale("en", "fr", "de", "pt-BR"))
+			r.GET("/x", func(c *gin.Context) { got = LocaleFromContext(c) })
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			if tt.header != "" {
+				req.Header.Set("Accept-Language", tt.header)
+			}
+			serve(r, req)
+
+			if got != tt.want {
+				t.Errorf("Accept-Language %q: locale = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type logFieldsKey struct{}
+
+type logFields struct {
+	mu     sync.Mutex
+	fields []zap.Field
+}
+
+// AddLogField adds fields to the api summary Logger writes for the request
+// ctx belongs to, so handlers can enrich the one summary line instead of
+// logging their own. It is safe from any goroutine and a no-op outside
+// Logger. ctx may be the request context or the *gin.Context.
+func AddLogField(ctx context.Context, fields ...zap.Field) {
+	ctx = requestContext(ctx)
+	if lf, ok := ctx.Value(logFieldsKey{}).(*logFields); ok {
+		lf.mu.Lock()
+		lf.fields = append(lf.fields, fields...)
+		lf.mu.Unlock()
+	}
+}
+
+func startLogFields(c *gin.Context) *logFields {
+	lf := &logFields{}
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), logFieldsKey{}, lf))
+	return lf
+}
+
+func (lf *logFields) get() []zap.Field {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return append([]zap.Field(nil), lf.fields...)
+}
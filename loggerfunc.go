@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WithLoggerFunc resolves the logger per request, e.g. for per-tenant
+// routing. A nil result falls back to the logger given at construction.
+// Logger resolves it after the handler has run.
+func WithLoggerFunc(fn func(c *gin.Context) *zap.Logger) Option {
+	return func(o *options) {
+		o.loggerFunc = fn
+	}
+}
+
+func (o *options) loggerFor(c *gin.Context, fallback *zap.Logger) *zap.Logger {
+	if o.loggerFunc == nil {
+		return fallback
+	}
+	if l := o.loggerFunc(c); l != nil {
+		return l
+	}
+	return fallback
+}
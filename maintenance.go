@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const maintenanceMsg = "maintenance"
+
+type MaintenanceOptions struct {
+	// Allow lists path prefixes that stay live, in addition to health checks.
+	Allow []string
+	// RetryAfter is sent as the Retry-After header, rounded up to whole
+	// seconds; defaults to 60s.
+	RetryAfter time.Duration
+	// Message is the error message in the response body.
+	Message string
+}
+
+// Maintenance aborts every non-exempt request with 503 while enabled is set.
+// The flag can be flipped at runtime.
+func Maintenance(logger *zap.Logger, enabled *atomic.Bool, opts MaintenanceOptions) gin.HandlerFunc {
+	retryAfter := opts.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = time.Minute
+	}
+	message := opts.Message
+	if message == "" {
+		message = "service under maintenance"
+	}
+	seconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if !enabled.Load() || isHealthCheck(path) || hasAnyPrefix(path, opts.Allow) {
+			c.Next()
+			return
+		}
+		logger.Info(maintenanceMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("method", c.Request.Method),
+			zap.String("path_uri", routePath(c)),
+		)
+		c.Header("Retry-After", seconds)
+		abortWithError(c, http.StatusServiceUnavailable, message)
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
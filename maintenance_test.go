@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestMaintenance(t *testing.T) {
+	tests := []struct {
+		name           string
+		disabled       bool
+		path           string
+		retryAfter     time.Duration
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{"disabled", true, "/x", 0, http.StatusOK, ""},
+		{"default retry after", false, "/x", 0, http.StatusServiceUnavailable, "60"},
+		{"whole seconds", false, "/x", 30 * time.Second, http.StatusServiceUnavailable, "30"},
+		{"sub-second", false, "/x", 200 * time.Millisecond, http.StatusServiceUnavailable, "1"},
+		{"rounded up", false, "/x", 1500 * time.Millisecond, http.StatusServiceUnavailable, "2"},
+		{"allowed prefix", false, "/admin/flag", 0, http.StatusOK, ""},
+		{"health check", false, "/readiness", 0, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var enabled atomic.Bool
+			enabled.Store(!tt.disabled)
+			r := gin.New()
+			r.Use(Maintenance(zap.NewNop(), &enabled, MaintenanceOptions{Allow: []string{"/admin/"}, RetryAfter: tt.retryAfter}))
+			r.NoRoute(func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := serve(r, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
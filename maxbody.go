@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const bodyLimitExceededMsg = "body_limit_exceeded"
+
+// MaxBodySize limits request bodies to limit bytes. A declared Content-Length
+// over the limit is rejected with 413 before anything is read; other bodies
+// are wrapped in http.MaxBytesReader, so handlers get a *http.MaxBytesError
+// from Read once the limit is crossed, also when a body-reading middleware
+// such as RequestLogger ran in between. Both cases log body_limit_exceeded.
+func MaxBodySize(logger *zap.Logger, limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			logBodyLimit(logger, c, limit, "content_length")
+			abortWithError(c, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = &limitedBody{
+				ReadCloser: http.MaxBytesReader(c.Writer, c.Request.Body, limit),
+				onExceeded: func() { logBodyLimit(logger, c, limit, "stream") },
+			}
+		}
+		c.Next()
+	}
+}
+
+func logBodyLimit(logger *zap.Logger, c *gin.Context, limit int64, source string) {
+	logger.Warn(bodyLimitExceededMsg,
+		zap.String("xid", getRequestID(c)),
+		zap.String("method", c.Request.Method),
+		zap.String("path_uri", routePath(c)),
+		zap.Int64("limit", limit),
+		zap.Int64("content_length", c.Request.ContentLength),
+		zap.String("source", source),
+	)
+}
+
+// limitedBody calls onExceeded the first time the limit is hit.
+type limitedBody struct {
+	io.ReadCloser
+	onExceeded func()
+	once       sync.Once
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		b.once.Do(b.onExceeded)
+	}
+	return n, err
+}
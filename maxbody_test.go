@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		chunked       bool // no declared Content-Length
+		requestLogger bool // RequestLogger reads the body before the handler
+		wantStatus    int
+		wantTooLarge  bool
+		wantSource    string
+	}{
+		{"under limit", "0123456789", false, false, http.StatusOK, false, ""},
+		{"declared over limit", strings.Repeat("x", 11), false, false, http.StatusRequestEntityTooLarge, false, "content_length"},
+		{"chunked under limit", "0123456789", true, false, http.StatusOK, false, ""},
+		{"chunked over limit", strings.Repeat("x", 11), true, false, http.StatusOK, true, "stream"},
+		{"chunked over limit, body logged first", strings.Repeat("x", 11), true, true, http.StatusOK, true, "stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			var readErr error
+			handlerCalled := false
+			r := gin.New()
+			r.Use(MaxBodySize(logger, 10))
+			if tt.requestLogger {
+				r.Use(RequestLogger(logger))
+			}
+			r.POST("/x", func(c *gin.Context) {
+				handlerCalled = true
+				_, readErr = io.ReadAll(c.Request.Body)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/x", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			w := serve(r, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if handlerCalled == (tt.wantStatus == http.StatusRequestEntityTooLarge) {
+				t.Errorf("handler called = %v", handlerCalled)
+			}
+			var tooLarge *http.MaxBytesError
+			if got := errors.As(readErr, &tooLarge); got != tt.wantTooLarge {
+				t.Errorf("handler read error = %v, want MaxBytesError: %v", readErr, tt.wantTooLarge)
+			}
+			limits := logs.FilterMessage(bodyLimitExceededMsg).All()
+			if tt.wantSource == "" {
+				if len(limits) != 0 {
+					t.Errorf("logged %d body limits, want none", len(limits))
+				}
+				return
+			}
+			if len(limits) != 1 {
+				t.Fatalf("logged %d body limits, want 1", len(limits))
+			}
+			if source := limits[0].ContextMap()["source"]; source != tt.wantSource {
+				t.Errorf("source = %v, want %q", source, tt.wantSource)
+			}
+		})
+	}
+}
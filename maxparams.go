@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	tooManyParamsMsg = "too_many_params"
+	// maxParamsScan is how much of a form body MaxParams reads up front.
+	maxParamsScan = 64 << 10
+)
+
+var ErrTooManyFields = errors.New("middleware: too many form fields")
+
+// MaxParams rejects with 400 requests carrying more than maxQuery query
+// parameters or more than maxFields urlencoded/multipart form fields. Fields
+// are counted as the body streams, without parsing or buffering it: the first
+// maxParamsScan bytes are checked up front, and a body past them that crosses
+// the limit fails the handler's read with ErrTooManyFields. A zero limit
+// disables that check.
+func MaxParams(logger *zap.Logger, maxQuery, maxFields int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxQuery > 0 {
+			if n := countPairs(c.Request.URL.RawQuery, maxQuery); n > maxQuery {
+				rejectTooManyParams(logger, c, "query_params", n)
+				return
+			}
+		}
+		if maxFields > 0 {
+			if fc := newFieldCounter(c, maxFields); fc != nil {
+				prefix, err := io.ReadAll(io.LimitReader(fc, maxParamsScan))
+				if errors.Is(err, ErrTooManyFields) {
+					rejectTooManyParams(logger, c, "form_fields", fc.count())
+					return
+				}
+				if err != nil {
+					c.Request.Body = replayBody(prefix, err, c.Request.Body)
+				} else {
+					c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(prefix), fc), c.Request.Body}
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// countPairs counts &-separated pairs, stopping once past limit.
+func countPairs(query string, limit int) int {
+	n := 0
+	for query != "" && n <= limit {
+		var pair string
+		pair, query, _ = strings.Cut(query, "&")
+		if pair != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// fieldCounter counts form fields in the body as it is read, failing with
+// ErrTooManyFields once there are more than limit. Multipart parts are counted
+// by their boundary delimiters, urlencoded fields by their separators.
+type fieldCounter struct {
+	r     io.Reader
+	limit int
+	delim []byte
+	tail  []byte
+	n     int
+	open  bool
+}
+
+func newFieldCounter(c *gin.Context, limit int) *fieldCounter {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil
+	}
+	fc := &fieldCounter{r: c.Request.Body, limit: limit}
+	mediaType, params, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case gin.MIMEPOSTForm:
+		return fc
+	case gin.MIMEMultipartPOSTForm:
+		if params["boundary"] == "" {
+			return nil
+		}
+		fc.delim = []byte("--" + params["boundary"])
+		return fc
+	}
+	return nil
+}
+
+func (fc *fieldCounter) Read(p []byte) (int, error) {
+	if fc.count() > fc.limit {
+		return 0, ErrTooManyFields
+	}
+	n, err := fc.r.Read(p)
+	if fc.delim != nil {
+		buf := append(fc.tail, p[:n]...)
+		fc.n += bytes.Count(buf, fc.delim)
+		if keep := len(fc.delim) - 1; len(buf) > keep {
+			buf = buf[len(buf)-keep:]
+		}
+		fc.tail = append(fc.tail[:0], buf...)
+	} else {
+		for _, b := range p[:n] {
+			if b != '&' {
+				fc.open = true
+			} else if fc.open {
+				fc.n++
+				fc.open = false
+			}
+		}
+	}
+	if fc.count() > fc.limit {
+		return n, ErrTooManyFields
+	}
+	return n, err
+}
+
+// count is the number of fields seen so far.
+func (fc *fieldCounter) count() int {
+	if fc.delim != nil {
+		// Every part opens with a delimiter and the last one closes.
+		if fc.n == 0 {
+			return 0
+		}
+		return fc.n - 1
+	}
+	if fc.open {
+		return fc.n + 1
+	}
+	return fc.n
+}
+
+func rejectTooManyParams(logger *zap.Logger, c *gin.Context, kind string, n int) {
+	logger.Warn(tooManyParamsMsg,
+		zap.String("xid", getRequestID(c)),
+		zap.String("method", c.Request.Method),
+		zap.String("path_uri", routePath(c)),
+		zap.Int(kind, n),
+	)
+	abortWithError(c, http.StatusBadRequest, "too many parameters")
+}
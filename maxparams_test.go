@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func multipartBody(t *testing.T, fields int) (string, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for i := 0; i < fields; i++ {
+		if err := mw.WriteField("f", "v"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mw.Close()
+	return mw.FormDataContentType(), buf.Bytes()
+}
+
+func TestMaxParams(t *testing.T) {
+	mpOK, mpOKBody := multipartBody(t, 3)
+	mpOver, mpOverBody := multipartBody(t, 4)
+	// Over the limit only past the up-front scan, so the handler's read fails.
+	late := "a=" + strings.Repeat("x", maxParamsScan) + "&b=1&c=1&d=1"
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        []byte
+		wantStatus  int
+		wantReadErr error
+		wantBody    bool // the handler reads the whole body back
+	}{
+		{"no params", "/x", "", nil, http.StatusOK, nil, false},
+		{"query at limit", "/x?a=1&b=2&c=3", "", nil, http.StatusOK, nil, false},
+		{"query over limit", "/x?a=1&b=2&c=3&d=4", "", nil, http.StatusBadRequest, nil, false},
+		{"empty pairs ignored", "/x?a=1&&&b=2&c=3&", "", nil, http.StatusOK, nil, false},
+		{"form at limit", "/x", gin.MIMEPOSTForm, []byte("a=1&b=2&c=3"), http.StatusOK, nil, true},
+		{"form over limit", "/x", gin.MIMEPOSTForm, []byte("a=1&b=2&c=3&d=4"), http.StatusBadRequest, nil, false},
+		{"multipart at limit", "/x", mpOK, mpOKBody, http.StatusOK, nil, true},
+		{"multipart over limit", "/x", mpOver, mpOverBody, http.StatusBadRequest, nil, false},
+		{"json not counted", "/x", gin.MIMEJSON, []byte(`{"a":1,"b":2,"c":3,"d":4}`), http.StatusOK, nil, true},
+		{"form over limit late", "/x", gin.MIMEPOSTForm, []byte(late), http.StatusOK, ErrTooManyFields, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			var (
+				got     []byte
+				readErr error
+			)
+			r := gin.New()
+			r.Use(MaxParams(logger, 3, 3))
+			r.POST("/x", func(c *gin.Context) {
+				got, readErr = io.ReadAll(c.Request.Body)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, bytes.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := serve(r, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if rejected := logs.FilterMessage(tooManyParamsMsg).Len() > 0; rejected != (tt.wantStatus == http.StatusBadRequest) {
+				t.Errorf("logged %s = %v", tooManyParamsMsg, rejected)
+			}
+			if !errors.Is(readErr, tt.wantReadErr) {
+				t.Errorf("handler read error = %v, want %v", readErr, tt.wantReadErr)
+			}
+			if tt.wantBody && !bytes.Equal(got, tt.body) {
+				t.Errorf("handler read %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
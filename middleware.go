@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -29,88 +32,423 @@ func RequestID() gin.HandlerFunc {
 		}
 		c.Set(X_REQUEST_ID, xid)
 		c.Request.Header.Set(X_REQUEST_ID, xid)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIDKey{}, xid))
 		c.Next()
 	}
 }
 
-func Logger(logger *zap.Logger) gin.HandlerFunc {
+func Logger(logger *zap.Logger, opts ...Option) gin.HandlerFunc {
+	o := newOptions(opts)
 	return func(c *gin.Context) {
-		if strings.HasPrefix(c.FullPath(), "/liveness") || strings.HasPrefix(c.FullPath(), "/readiness") {
+		if isHealthCheck(c.FullPath()) {
 			c.Next()
 			return
 		}
 
 		start := time.Now()
+		if o.beforeRequest != nil {
+			runHook(logger, c, "before_request", func() { o.beforeRequest(c) })
+		}
+		var capture *bodyCapture
+		if o.combined {
+			capture = o.startBodyCapture(logger, c)
+		}
+		if o.slowStack != nil {
+			defer o.slowStack.watch(logger, c, o.pathOf(c))()
+		}
+		var dbCalls *atomic.Int64
+		if o.dbCalls {
+			dbCalls = startDBCalls(c)
+		}
+		added := startLogFields(c)
+		var timing *ioTiming
+		if o.ioTiming {
+			timing = startIOTiming(c)
+		}
+		var deadline *requestDeadline
+		if o.deadline {
+			deadline = startDeadline(c, start)
+		}
+		defer func() {
+			r := recover()
+			status := finalStatus(c, r)
+			extra := append(capture.fields(c), dbCallsFields(dbCalls)...)
+			extra = append(extra, deadline.fields()...)
+			extra = append(extra, timing.fields()...)
+			logSummary(o.loggerFor(c, logger), o, c, start, status, append(extra, added.get()...)...)
+			if o.errorDedup != nil && len(c.Errors) > 0 {
+				o.errorDedup.log(o.loggerFor(c, logger), c)
+			}
+			if o.traceSink != nil {
+				o.traceSink(traceEvent(c, o.pathOf(c), start, status))
+			}
+			if o.errorCallback != nil && status >= http.StatusInternalServerError {
+				cc := c.Copy()
+				go runHook(logger, cc, "error_callback", func() { o.errorCallback(cc, status) })
+			}
+			if o.afterRequest != nil {
+				latency := time.Since(start)
+				runHook(logger, c, "after_request", func() { o.afterRequest(c, latency, status) })
+			}
+			if r != nil {
+				panic(r)
+			}
+		}()
 		c.Next()
-		path := c.FullPath()
-		method := c.Request.Method
-		status := c.Writer.Status()
-		logger.Info(fmt.Sprintf("%s: method=%s, path=%s, status=%d", apiSummary, method, path, status),
-			zap.String("xid", getRequestID(c)),
-			zap.String("method", method),
-			zap.String("path_uri", path),
-			zap.Int("status", c.Writer.Status()),
-			zap.String("latency", time.Since(start).String()),
-		)
 	}
 }
 
-func RequestLogger(logger *zap.Logger) gin.HandlerFunc {
+func logSummary(logger *zap.Logger, o *options, c *gin.Context, start time.Time, status int, extra ...zap.Field) {
+	end := time.Now()
+	level, zf, ok := o.summary(logger, c, start, end, status, extra)
+	if o.shadow != nil {
+		o.shadow.compare(logger, c, start, end, status, extra, level, zf, ok)
+	}
+	if !ok {
+		return
+	}
+	path := o.pathOf(c)
+	if ce := logger.Check(level, fmt.Sprintf("%s: method=%s, path=%s, status=%d", apiSummary, c.Request.Method, path, status)); ce != nil {
+		ce.Write(zf...)
+	}
+}
+
+// summary builds the api summary for o. ok is false when it is sampled out
+// or the error throttle drops it.
+func (o *options) summary(logger *zap.Logger, c *gin.Context, start, end time.Time, status int, extra []zap.Field) (level zapcore.Level, zf []zap.Field, ok bool) {
+	path := o.pathOf(c)
+	method := c.Request.Method
+	zf = []zap.Field{
+		zap.String("xid", getRequestID(c)),
+		zap.String("method", method),
+		zap.String("path_uri", path),
+		zap.Int("status", status),
+	}
+	zf = append(zf, o.latencyFields(start, end)...)
+	if o.queueTime {
+		zf = append(zf, queueTimeFields(c, start)...)
+	}
+	if o.instance != "" {
+		zf = append(zf, zap.String("instance", o.instance))
+	}
+	if o.serverVersion != "" {
+		zf = append(zf, zap.String("server_version", o.serverVersion))
+	}
+	if o.requestLine {
+		zf = append(zf, zap.String("request_line", o.requestLineOf(c)))
+	}
+	if o.negotiation {
+		zf = append(zf, negotiationFields(c)...)
+	}
+	if o.routeMeta != nil {
+		zf = append(zf, o.routeMeta.fields(c)...)
+	}
+	if o.bots != nil {
+		zf = append(zf, o.botFields(c)...)
+	}
+	if o.baggageFields != nil {
+		zf = append(zf, o.baggageLogFields(c)...)
+	}
+	if o.cacheStatus {
+		zf = append(zf, cacheStatusFields(c)...)
+	}
+	if o.sizeBuckets != nil {
+		zf = append(zf, o.sizeBuckets.fields(c)...)
+	}
+	if o.retryHeader != "" {
+		zf = append(zf, o.clientRetryFields(c)...)
+	}
+	if o.internalNets != nil {
+		zf = append(zf, o.networkFields(c)...)
+	}
+	if o.routeParams {
+		zf = append(zf, o.routeParamFields(c)...)
+	}
+	if o.contextKeys != nil {
+		zf = append(zf, o.contextKeyFields(c)...)
+	}
+	zf = append(zf, spanIDFields(c)...)
+	zf = append(zf, tenantFields(c)...)
+	zf = append(zf, userFields(c)...)
+	zf = append(zf, extra...)
+	level = zapcore.InfoLevel
+	if l, ok := o.pathLevels[path]; ok {
+		level = l
+	}
+	if o.classify != nil {
+		outcome := o.classify(c)
+		if l := outcome.level(); l > level {
+			level = l
+		}
+		zf = append(zf, zap.Stringer("outcome", outcome))
+	}
+	if o.gcpFields {
+		zf = append(zf, o.gcpLogFields(c, level, end.Sub(start), status)...)
+	}
+	if !o.logSampled(c, status) {
+		return level, zf, false
+	}
+	if level >= zapcore.ErrorLevel && o.errorThrottle != nil && !o.errorThrottle.allow(logger) {
+		return level, zf, false
+	}
+	return level, zf, true
+}
+
+func RequestLogger(logger *zap.Logger, opts ...Option) gin.HandlerFunc {
+	o := newOptions(opts)
 	return func(c *gin.Context) {
-		if strings.HasPrefix(c.FullPath(), "/liveness") || strings.HasPrefix(c.FullPath(), "/readiness") {
+		logger := o.loggerFor(c, logger)
+		if isHealthCheck(c.FullPath()) {
 			c.Next()
 			return
 		}
 
-		header, _ := json.Marshal(c.Request.Header)
-		body, _ := io.ReadAll(c.Request.Body)
 		zf := []zap.Field{
 			zap.String("xid", getRequestID(c)),
 			zap.String("method", c.Request.Method),
-			zap.String("path_uri", c.FullPath()),
-			zap.String("header", string(header)),
-			zap.String("body", string(body)),
+			zap.String("path_uri", o.pathOf(c)),
+		}
+		forced := o.forceDebug(c)
+		logBody := forced || (logger.Level() != zapcore.InfoLevel && o.sampleBody(c))
+		multipartFiles := o.multipartMaxMemory > 0 && c.ContentType() == gin.MIMEMultipartPOSTForm
+		var (
+			body  []byte
+			spool *spooledBody
+		)
+		if !multipartFiles && (logBody || o.checkContentLength || o.bodyHash || o.validateJSONBody) {
+			if o.spoolThreshold > 0 {
+				body, spool = o.readOrSpoolBody(logger, c)
+			} else {
+				body = readRequestBody(c)
+			}
+		}
+		if spool != nil {
+			defer spool.remove()
+		}
+		bodyLen := int64(len(body))
+		if spool != nil {
+			bodyLen = spool.size
+			zf = append(zf, zap.Bool("body_spooled", true), zap.Int64("body_size", spool.size))
+		}
+		if logBody {
+			header, _ := json.Marshal(c.Request.Header)
+			zf = append(zf, zap.String("header", string(header)))
+			if !multipartFiles && !o.hideBody && spool == nil {
+				zf = append(zf, zap.String("body", string(body)))
+			}
+		}
+		if o.bodyHash && spool != nil {
+			zf = append(zf, zap.String("body_hash", o.formatHash(spool.sum)))
+		} else if o.bodyHash && !multipartFiles {
+			zf = append(zf, zap.String("body_hash", o.hashBody(body)))
+		}
+		if multipartFiles {
+			parsed := c.Request.MultipartForm == nil
+			zf = append(zf, o.multipartFields(c)...)
+			// net/http only cleans up the form of the request it created,
+			// not of the copies middleware makes with WithContext.
+			if form := c.Request.MultipartForm; parsed && form != nil {
+				defer form.RemoveAll()
+			}
 		}
-		c.Request.Body = io.NopCloser(bytes.NewReader(body))
 
-		if logger.Level() == zapcore.InfoLevel {
-			logger.Info(requestInfoMsg, zf[:3]...)
-		} else {
+		switch {
+		case forced:
+			logger.Info(requestInfoMsg, append(zf, zap.Bool("forced_debug", true))...)
+		case logger.Level() == zapcore.InfoLevel:
+			logger.Info(requestInfoMsg, zf...)
+		default:
 			logger.Debug(requestInfoMsg, zf...)
 		}
 
+		if o.checkContentLength && !multipartFiles && c.Request.ContentLength >= 0 && bodyLen != c.Request.ContentLength {
+			logger.Warn(requestInfoMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("method", c.Request.Method),
+				zap.String("path_uri", o.pathOf(c)),
+				zap.Bool("content_length_mismatch", true),
+				zap.Int64("content_length", c.Request.ContentLength),
+				zap.Int64("body_length", bodyLen),
+			)
+			if o.rejectContentLengthMismatch {
+				abortWithError(c, http.StatusBadRequest, "content length mismatch")
+				return
+			}
+		}
+		if o.validateJSONBody && spool == nil && !o.checkJSONBody(c, body) {
+			return
+		}
+
 		c.Next()
 	}
 }
 
+// readRequestBody reads the whole body and puts it back for the handler. A
+// read error, such as *http.MaxBytesError from MaxBodySize, is replayed to the
+// handler after the bytes that were read instead of silently truncating.
+func readRequestBody(c *gin.Context) []byte {
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = replayBody(body, err, c.Request.Body)
+	return body
+}
+
+// replayBody reads as prefix followed by err, or EOF when err is nil, and
+// closes orig.
+func replayBody(prefix []byte, err error, orig io.Closer) io.ReadCloser {
+	if err == nil {
+		return readCloser{bytes.NewReader(prefix), orig}
+	}
+	return readCloser{io.MultiReader(bytes.NewReader(prefix), errReader{err}), orig}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
 type responseBodyWriter struct {
 	gin.ResponseWriter
 	body *bytes.Buffer
+	// limit caps how much of the body is kept; zero keeps everything.
+	limit int
+	// recheck, when set, is asked once on the first write, with the headers
+	// in place, whether to keep capturing.
+	recheck func() bool
+	skipped bool
+	// ctx, when set, is the request context; writes made after it is done
+	// are counted in lateWrites.
+	ctx        context.Context
+	lateWrites int
+	// writeTime is the time spent in the underlying Write.
+	writeTime time.Duration
 }
 
-func (r responseBodyWriter) Write(b []byte) (int, error) {
-	r.body.Write(b)
-	return r.ResponseWriter.Write(b)
+func (r *responseBodyWriter) Write(b []byte) (int, error) {
+	if r.ctx != nil && r.ctx.Err() != nil {
+		r.lateWrites++
+	}
+	if r.recheck != nil {
+		// recheck may only call capture off, never back on.
+		if !r.recheck() {
+			r.skipped = true
+		}
+		r.recheck = nil
+	}
+	switch room := r.limit - r.body.Len(); {
+	case r.skipped:
+	case r.limit <= 0:
+		r.body.Write(b)
+	case room > 0:
+		if room > len(b) {
+			room = len(b)
+		}
+		r.body.Write(b[:room])
+	}
+	start := time.Now()
+	n, err := r.ResponseWriter.Write(b)
+	r.writeTime += time.Since(start)
+	return n, err
 }
 
-func ResponseLogger(logger *zap.Logger) gin.HandlerFunc {
+func ResponseLogger(logger *zap.Logger, opts ...Option) gin.HandlerFunc {
+	o := newOptions(opts)
 	return func(c *gin.Context) {
-		if logger.Level() == zapcore.InfoLevel || strings.HasPrefix(c.FullPath(), "/liveness") || strings.HasPrefix(c.FullPath(), "/readiness") {
+		logger := o.loggerFor(c, logger)
+		if isHealthCheck(c.FullPath()) || isWebSocketUpgrade(c) {
+			c.Next()
+			return
+		}
+		forced := o.forceDebug(c)
+		logBody := forced || (logger.Level() != zapcore.InfoLevel && o.sampleBody(c))
+		slowCapture := o.slowBodyThreshold > 0
+		if (!logBody && !slowCapture && !o.writeAfterCancel) || (o.captureBodyWhen != nil && !o.captureBodyWhen(c)) {
 			c.Next()
 			return
 		}
 
+		var reqBody []byte
+		if slowCapture {
+			reqBody = peekRequestBody(c, o.slowBodyMaxBytes)
+		}
 		w := &responseBodyWriter{body: &bytes.Buffer{}, ResponseWriter: c.Writer}
+		if !logBody {
+			w.limit = o.slowBodyMaxBytes
+		}
+		if o.captureBodyWhen != nil {
+			w.recheck = func() bool { return o.captureBodyWhen(c) }
+		}
+		if o.writeAfterCancel {
+			w.ctx = c.Request.Context()
+			w.skipped = !logBody && !slowCapture
+		}
+		start := time.Now()
 		c.Writer = w
 		c.Next()
-		logger.Debug(responseInfoMsg,
-			zap.String("xid", getRequestID(c)),
-			zap.String("body", w.body.String()),
-			zap.Int("status", w.Status()),
-		)
+		latency := time.Since(start)
+		if w.lateWrites > 0 {
+			logger.Warn(responseInfoMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("method", c.Request.Method),
+				zap.String("path_uri", o.pathOf(c)),
+				zap.Bool("wrote_after_cancel", true),
+				zap.Int("late_writes", w.lateWrites),
+			)
+		}
+		if w.skipped {
+			return
+		}
+
+		if logBody {
+			c.Set(responseBodyKey, w.body.Bytes())
+			zf := []zap.Field{
+				zap.String("xid", getRequestID(c)),
+				zap.String("body", w.body.String()),
+				zap.Int("status", w.Status()),
+			}
+			if o.setCookies {
+				zf = append(zf, setCookieFields(c)...)
+			}
+			if forced {
+				logger.Info(responseInfoMsg, append(zf, zap.Bool("forced_debug", true))...)
+			} else {
+				logger.Debug(responseInfoMsg, zf...)
+			}
+			if o.validateJSON {
+				o.validateJSONResponse(logger, c, w.body.Bytes())
+			}
+			if o.schemaDrift != nil {
+				o.schemaDrift.check(logger, c, w.body.Bytes())
+			}
+		}
+		if slowCapture && latency >= o.slowBodyThreshold {
+			o.logSlowBodies(logger, c, latency, reqBody, w.body.Bytes())
+		}
+	}
+}
+
+// finalStatus is the status the client gets, given what a deferred recover()
+// returned: a panic before anything was written becomes a 500.
+func finalStatus(c *gin.Context, recovered interface{}) int {
+	if recovered != nil && !c.Writer.Written() {
+		return http.StatusInternalServerError
 	}
+	return c.Writer.Status()
+}
+
+func isHealthCheck(path string) bool {
+	return strings.HasPrefix(path, "/liveness") || strings.HasPrefix(path, "/readiness")
 }
 
 func getRequestID(c *gin.Context) string {
 	return c.Request.Header.Get(X_REQUEST_ID)
 }
+
+// requestContext unwraps a *gin.Context into its request context, where the
+// request-scoped values live, so the context helpers accept either.
+func requestContext(ctx context.Context) context.Context {
+	if c, ok := ctx.(*gin.Context); ok {
+		return c.Request.Context()
+	}
+	return ctx
+}
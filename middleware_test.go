@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+func observedLogger(level zapcore.Level) (*zap.Logger, *observer.ObservedLogs) {
+	core, logs := observer.New(level)
+	return zap.New(core), logs
+}
+
+func serve(r *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// summaries returns the api summary entries logged so far.
+func summaries(logs *observer.ObservedLogs) []observer.LoggedEntry {
+	var out []observer.LoggedEntry
+	for _, e := range logs.All() {
+		if strings.HasPrefix(e.Message, apiSummary) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestLoggerSummaryOnPanic(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		recovery   string // where gin.Recovery sits relative to Logger
+		wantStatus int64
+	}{
+		{"ok", func(c *gin.Context) { c.String(http.StatusOK, "ok") }, "outside", http.StatusOK},
+		{"panic, recovery outside", func(c *gin.Context) { panic("boom") }, "outside", http.StatusInternalServerError},
+		{"panic, recovery inside", func(c *gin.Context) { panic("boom") }, "inside", http.StatusInternalServerError},
+		{"panic after write", func(c *gin.Context) { c.String(http.StatusAccepted, "partial"); panic("boom") }, "outside", http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			r := gin.New()
+			if tt.recovery == "outside" {
+				r.Use(gin.Recovery(), Logger(logger))
+			} else {
+				r.Use(Logger(logger), gin.Recovery())
+			}
+			r.GET("/x", tt.handler)
+
+			serve(r, httptest.NewRequest(http.MethodGet, "/x", nil))
+
+			got := summaries(logs)
+			if len(got) != 1 {
+				t.Fatalf("got %d api summaries, want 1", len(got))
+			}
+			if status := got[0].ContextMap()["status"]; status != tt.wantStatus {
+				t.Errorf("status = %v, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestLoggerRepanics(t *testing.T) {
+	logger, _ := observedLogger(zapcore.DebugLevel)
+	r := gin.New()
+	r.Use(Logger(logger))
+	r.GET("/x", func(c *gin.Context) { panic("boom") })
+
+	defer func() {
+		if recover() == nil {
+			t.Error("panic was swallowed")
+		}
+	}()
+	serve(r, httptest.NewRequest(http.MethodGet, "/x", nil))
+}
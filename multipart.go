@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type fileMeta struct {
+	Field       string `json:"field"`
+	Filename    string `json:"filename"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+// WithMultipartFiles makes RequestLogger parse multipart/form-data requests,
+// keeping up to maxMemory bytes in memory, and log a files array of part
+// metadata in place of the raw body. The parsed form stays on c.Request, so
+// c.FormFile and c.MultipartForm do not re-read the body; files spilled to
+// disk are removed once the handlers return.
+func WithMultipartFiles(maxMemory int64) Option {
+	return func(o *options) {
+		o.multipartMaxMemory = maxMemory
+	}
+}
+
+func (o *options) multipartFields(c *gin.Context) []zap.Field {
+	if err := c.Request.ParseMultipartForm(o.multipartMaxMemory); err != nil {
+		return []zap.Field{zap.String("multipart_error", err.Error())}
+	}
+	var files []fileMeta
+	for field, headers := range c.Request.MultipartForm.File {
+		for _, fh := range headers {
+			files = append(files, fileMeta{
+				Field:       field,
+				Filename:    fh.Filename,
+				Size:        fh.Size,
+				ContentType: fh.Header.Get("Content-Type"),
+			})
+		}
+	}
+	return []zap.Field{zap.Any("files", files)}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRequestLoggerMultipartFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxMemory int64
+	}{
+		{"in memory", 1 << 20},
+		{"spilled to disk", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("TMPDIR", dir)
+
+			var buf bytes.Buffer
+			mw := multipart.NewWriter(&buf)
+			fw, _ := mw.CreateFormFile("upload", "a.txt")
+			fw.Write(bytes.Repeat([]byte("x"), 4096))
+			mw.WriteField("name", "a")
+			mw.Close()
+
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			var got []byte
+			r := gin.New()
+			r.Use(RequestID(), RequestLogger(logger, WithMultipartFiles(tt.maxMemory)))
+			r.POST("/x", func(c *gin.Context) {
+				fh, err := c.FormFile("upload")
+				if err != nil {
+					t.Fatal(err)
+				}
+				f, err := fh.Open()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, _ = io.ReadAll(f)
+				f.Close()
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/x", &buf)
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			serve(r, req)
+
+			if len(got) != 4096 {
+				t.Errorf("handler read %d bytes of the upload, want 4096", len(got))
+			}
+			entries := logs.FilterMessage(requestInfoMsg).All()
+			if len(entries) != 1 {
+				t.Fatalf("got %d request logs, want 1", len(entries))
+			}
+			files, _ := entries[0].ContextMap()["files"].([]fileMeta)
+			if len(files) != 1 || files[0].Size != 4096 {
+				t.Errorf("files = %v, want one upload", entries[0].ContextMap()["files"])
+			}
+			left, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(left) != 0 {
+				t.Errorf("temp dir still holds %d files", len(left))
+			}
+		})
+	}
+}
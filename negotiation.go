@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WithContentNegotiation adds the request's content_type and accept fields to
+// the api summary when present.
+func WithContentNegotiation() Option {
+	return func(o *options) {
+		o.negotiation = true
+	}
+}
+
+func negotiationFields(c *gin.Context) []zap.Field {
+	var zf []zap.Field
+	if ct := c.ContentType(); ct != "" {
+		zf = append(zf, zap.String("content_type", ct))
+	}
+	if accept := c.GetHeader("Accept"); accept != "" {
+		zf = append(zf, zap.String("accept", accept))
+	}
+	return zf
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WithNetworkTag makes Logger log network=internal when c.ClientIP(), which
+// honours the engine's trusted proxies, falls in one of cidrs, and
+// network=external otherwise. It panics on an invalid CIDR, like
+// regexp.MustCompile, since the list is fixed at startup.
+func WithNetworkTag(cidrs ...string) Option {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = n
+	}
+	return func(o *options) {
+		o.internalNets = nets
+	}
+}
+
+func (o *options) networkFields(c *gin.Context) []zap.Field {
+	network := "external"
+	if ip := net.ParseIP(c.ClientIP()); ip != nil {
+		for _, n := range o.internalNets {
+			if n.Contains(ip) {
+				network = "internal"
+				break
+			}
+		}
+	}
+	return []zap.Field{zap.String("network", network)}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxErrorMessage = 512
+
+// NormalizeErrors rewrites error responses (status >= 400) that are not
+// already an ErrorResponse into one, keeping the status and carrying over a
+// message from the original body when there is one. Other responses pass
+// through untouched.
+func NormalizeErrors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := newBufferedResponseWriter(c.Writer)
+		nextBuffered(c, w)
+
+		if w.status >= http.StatusBadRequest && !isErrorResponse(w.body.Bytes()) {
+			b, _ := json.Marshal(ErrorResponse{Error: ErrorBody{
+				Code:      w.status,
+				Message:   errorMessage(w.status, w.Header().Get("Content-Type"), w.body.Bytes()),
+				RequestID: getRequestID(c),
+			}})
+			w.body.Reset()
+			w.body.Write(b)
+			w.Header().Set("Content-Type", gin.MIMEJSON+"; charset=utf-8")
+			w.Header().Del("Content-Length")
+		}
+		w.flush()
+	}
+}
+
+func isErrorResponse(body []byte) bool {
+	var probe struct {
+		Error *ErrorBody `json:"error"`
+	}
+	return json.Unmarshal(body, &probe) == nil && probe.Error != nil && probe.Error.Message != ""
+}
+
+// errorMessage picks a message out of a non-canonical error body.
+func errorMessage(status int, contentType string, body []byte) string {
+	body = bytes.TrimSpace(body)
+	if isJSONContentType(contentType) {
+		var fields map[string]interface{}
+		if json.Unmarshal(body, &fields) == nil {
+			for _, k := range []string{"message", "error", "detail", "title"} {
+				if s, ok := fields[k].(string); ok && s != "" {
+					return s
+				}
+			}
+		}
+	} else if strings.HasPrefix(contentType, "text/plain") && len(body) > 0 && len(body) <= maxErrorMessage {
+		return string(body)
+	}
+	return http.StatusText(status)
+}
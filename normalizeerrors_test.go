@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNormalizeErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantBody   string
+	}{
+		{"success untouched", func(c *gin.Context) { c.String(http.StatusOK, "fine") },
+			http.StatusOK, "fine"},
+		{"json message", func(c *gin.Context) { c.JSON(http.StatusBadRequest, gin.H{"message": "bad input"}) },
+			http.StatusBadRequest, `{"error":{"code":400,"message":"bad input","request_id":"xid-1"}}`},
+		{"json detail", func(c *gin.Context) { c.JSON(http.StatusConflict, gin.H{"detail": "taken"}) },
+			http.StatusConflict, `{"error":{"code":409,"message":"taken","request_id":"xid-1"}}`},
+		{"plain text", func(c *gin.Context) { c.String(http.StatusForbidden, " no access\n") },
+			http.StatusForbidden, `{"error":{"code":403,"message":"no access","request_id":"xid-1"}}`},
+		{"oversized text", func(c *gin.Context) { c.String(http.StatusBadGateway, strings.Repeat("x", maxErrorMessage+1)) },
+			http.StatusBadGateway, `{"error":{"code":502,"message":"Bad Gateway","request_id":"xid-1"}}`},
+		{"empty body", func(c *gin.Context) { c.Status(http.StatusNotFound) },
+			http.StatusNotFound, `{"error":{"code":404,"message":"Not Found","request_id":"xid-1"}}`},
+		{"already canonical", func(c *gin.Context) { abortWithError(c, http.StatusUnauthorized, "login") },
+			http.StatusUnauthorized, `{"error":{"code":401,"message":"login","request_id":"xid-1"}}`},
+		{"panic", func(c *gin.Context) { panic("boom") },
+			http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(gin.Recovery(), NormalizeErrors())
+			r.GET("/x", tt.handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			req.Header.Set(X_REQUEST_ID, "xid-1")
+			w := serve(r, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
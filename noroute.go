@@ -0,0 +1,27 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// WithNoRoutePath logs path_uri as sentinel (e.g. "<no route>") for requests
+// that match no route. By default the raw request path is logged instead.
+func WithNoRoutePath(sentinel string) Option {
+	return func(o *options) {
+		o.noRoutePath = sentinel
+	}
+}
+
+func (o *options) pathOf(c *gin.Context) string {
+	if c.FullPath() == "" && o.noRoutePath != "" {
+		return o.noRoutePath
+	}
+	return routePath(c)
+}
+
+// routePath is what path_uri means everywhere: the matched route template,
+// or the raw request path when no route matched.
+func routePath(c *gin.Context) string {
+	if path := c.FullPath(); path != "" {
+		return path
+	}
+	return c.Request.URL.Path
+}
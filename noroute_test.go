@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestPathURIForUnmatchedRoutes(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		url  string
+		want string
+	}{
+		{"matched route logs the template", nil, "/users/42", "/users/:id"},
+		{"unmatched route logs the raw path", nil, "/nope/42", "/nope/42"},
+		{"unmatched route logs the sentinel", []Option{WithNoRoutePath("<no route>")}, "/nope/42", "<no route>"},
+		{"sentinel leaves matched routes alone", []Option{WithNoRoutePath("<no route>")}, "/users/42", "/users/:id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			r := gin.New()
+			r.Use(Logger(logger, tt.opts...), RequestLogger(logger, tt.opts...))
+			r.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			serve(r, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			if n := len(summaries(logs)); n != 1 {
+				t.Errorf("got %d api summaries, want 1", n)
+			}
+			if n := logs.FilterMessage(requestInfoMsg).Len(); n != 1 {
+				t.Errorf("got %d request logs, want 1", n)
+			}
+			for _, e := range logs.All() {
+				if got := e.ContextMap()["path_uri"]; got != tt.want {
+					t.Errorf("%s: path_uri = %q, want %q", e.Message, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type Option func(*options)
+
+type options struct {
+	routeMeta      *RouteMeta
+	validateJSON   bool
+	forceDebugWhen func(c *gin.Context) bool
+	requestLine    bool
+	redactQuery    map[string]bool
+	bodySampleRate map[string]float64
+	noRoutePath    string
+	bots           []Bot
+	latencyFormat  LatencyFormat
+	classify       func(c *gin.Context) Outcome
+	baggageFields  []string
+	pathLevels     map[string]zapcore.Level
+	combined       bool
+	instance       string
+	negotiation    bool
+	setCookies     bool
+	loggerFunc     func(c *gin.Context) *zap.Logger
+	traceSink      func(TraceEvent)
+	errorThrottle  *errorThrottle
+	slowStack      *slowStack
+	schemaDrift    *SchemaRecorder
+	bodyHash       bool
+	bodyHashLen    int
+	hideBody       bool
+	traceSampling  bool
+	errorDedup     *errorDedup
+	dbCalls        bool
+	cacheStatus    bool
+	deadline       bool
+	contextKeys    []string
+	queueTime      bool
+	routeParams    bool
+	redactParams   map[string]bool
+	shadow         *shadow
+	gcpFields      bool
+	internalNets   []*net.IPNet
+	retryHeader    string
+	sizeBuckets    *SizeBuckets
+	logSampling    bool
+	logSampleRate  float64
+	serverVersion  string
+	ioTiming       bool
+
+	captureBodyWhen  func(c *gin.Context) bool
+	writeAfterCancel bool
+
+	validateJSONBody bool
+	beforeRequest    func(c *gin.Context)
+	afterRequest     func(c *gin.Context, latency time.Duration, status int)
+	errorCallback    func(c *gin.Context, status int)
+
+	multipartMaxMemory int64
+	slowBodyThreshold  time.Duration
+	slowBodyMaxBytes   int
+	spoolThreshold     int64
+	spoolDir           string
+
+	checkContentLength          bool
+	rejectContentLengthMismatch bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
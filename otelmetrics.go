@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// OTelMetrics records request duration and in-flight requests on meter using
+// the OpenTelemetry HTTP server semantic convention names. With
+// WithOutcomeClassifier durations also carry an outcome attribute.
+func OTelMetrics(meter metric.Meter, opts ...Option) (gin.HandlerFunc, error) {
+	o := newOptions(opts)
+	duration, err := meter.Float64Histogram("http.server.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP server requests."),
+	)
+	if err != nil {
+		return nil, err
+	}
+	active, err := meter.Int64UpDownCounter("http.server.active_requests",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of active HTTP server requests."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		method := attribute.String("http.request.method", c.Request.Method)
+		active.Add(ctx, 1, metric.WithAttributes(method))
+		start := time.Now()
+		defer func() {
+			r := recover()
+			active.Add(ctx, -1, metric.WithAttributes(method))
+			attrs := []attribute.KeyValue{
+				method,
+				attribute.Int("http.response.status_code", finalStatus(c, r)),
+			}
+			if route := c.FullPath(); route != "" {
+				attrs = append(attrs, attribute.String("http.route", route))
+			}
+			if o.classify != nil {
+				attrs = append(attrs, attribute.String("outcome", o.classify(c).String()))
+			}
+			duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+			if r != nil {
+				panic(r)
+			}
+		}()
+		c.Next()
+	}, nil
+}
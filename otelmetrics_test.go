@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// recordingMeter keeps the attributes of every duration recorded.
+type recordingMeter struct {
+	noop.Meter
+	h *recordingHistogram
+}
+
+func (m recordingMeter) Float64Histogram(string, ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return m.h, nil
+}
+
+type recordingHistogram struct {
+	noop.Float64Histogram
+	mu    sync.Mutex
+	attrs []attribute.Set
+}
+
+func (h *recordingHistogram) Record(_ context.Context, _ float64, opts ...metric.RecordOption) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.attrs = append(h.attrs, metric.NewRecordConfig(opts).Attributes())
+}
+
+func TestOTelMetricsStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		wantStatus int64
+		wantRoute  string
+	}{
+		{"ok", func(c *gin.Context) { c.Status(http.StatusNoContent) }, http.StatusNoContent, "/users/:id"},
+		{"panic", func(c *gin.Context) { panic("boom") }, http.StatusInternalServerError, "/users/:id"},
+		{"panic after write", func(c *gin.Context) { c.String(http.StatusAccepted, "partial"); panic("boom") }, http.StatusAccepted, "/users/:id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &recordingHistogram{}
+			mw, err := OTelMetrics(recordingMeter{h: h})
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := gin.New()
+			r.Use(gin.Recovery(), mw)
+			r.GET("/users/:id", tt.handler)
+
+			w := serve(r, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+
+			if len(h.attrs) != 1 {
+				t.Fatalf("recorded %d durations, want 1", len(h.attrs))
+			}
+			status, _ := h.attrs[0].Value("http.response.status_code")
+			if status.AsInt64() != tt.wantStatus || int64(w.Code) != tt.wantStatus {
+				t.Errorf("recorded status %d, client got %d, want %d", status.AsInt64(), w.Code, tt.wantStatus)
+			}
+			if route, _ := h.attrs[0].Value("http.route"); route.AsString() != tt.wantRoute {
+				t.Errorf("http.route = %q, want %q", route.AsString(), tt.wantRoute)
+			}
+		})
+	}
+}
+
+func TestOTelMetricsRepanics(t *testing.T) {
+	mw, err := OTelMetrics(noop.NewMeterProvider().Meter("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.Use(mw)
+	r.GET("/x", func(c *gin.Context) { panic("boom") })
+
+	defer func() {
+		if recover() == nil {
+			t.Error("panic was swallowed")
+		}
+	}()
+	serve(r, httptest.NewRequest(http.MethodGet, "/x", nil))
+}
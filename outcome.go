@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+const responseBodyKey = "response_body"
+
+type Outcome int
+
+const (
+	Success Outcome = iota
+	ClientError
+	ServerError
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case ClientError:
+		return "client_error"
+	case ServerError:
+		return "server_error"
+	default:
+		return "success"
+	}
+}
+
+func (o Outcome) level() zapcore.Level {
+	switch o {
+	case ClientError:
+		return zapcore.WarnLevel
+	case ServerError:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
+// WithOutcomeClassifier decides the business outcome of a request after
+// c.Next(). The outcome raises the api summary level (Info, Warn, Error), adds
+// an outcome field, and labels OTelMetrics. CapturedResponseBody exposes the body
+// when ResponseLogger captured it.
+func WithOutcomeClassifier(fn func(c *gin.Context) Outcome) Option {
+	return func(o *options) {
+		o.classify = fn
+	}
+}
+
+// StatusOutcome is the default classification by HTTP status class.
+func StatusOutcome(c *gin.Context) Outcome {
+	switch status := c.Writer.Status(); {
+	case status >= 500:
+		return ServerError
+	case status >= 400:
+		return ClientError
+	default:
+		return Success
+	}
+}
+
+// CapturedResponseBody returns the response body buffered by ResponseLogger,
+// or nil when it was not captured.
+func CapturedResponseBody(c *gin.Context) []byte {
+	if v, ok := c.Get(responseBodyKey); ok {
+		return v.([]byte)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const paginationKey = "pagination"
+
+type PaginationOptions struct {
+	// LimitParam and OffsetParam default to "limit" and "offset".
+	LimitParam  string
+	OffsetParam string
+	// DefaultLimit defaults to 20 and MaxLimit to 100.
+	DefaultLimit int
+	MaxLimit     int
+	// MaxOffset clamps the offset when set.
+	MaxOffset int
+}
+
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// Pagination validates and clamps the limit and offset query parameters and
+// stores the result for PageFromContext. Negative or non-numeric values are
+// rejected with 400; a missing or zero limit uses DefaultLimit.
+func Pagination(opts PaginationOptions) gin.HandlerFunc {
+	if opts.LimitParam == "" {
+		opts.LimitParam = "limit"
+	}
+	if opts.OffsetParam == "" {
+		opts.OffsetParam = "offset"
+	}
+	if opts.DefaultLimit <= 0 {
+		opts.DefaultLimit = 20
+	}
+	if opts.MaxLimit <= 0 {
+		opts.MaxLimit = 100
+	}
+	return func(c *gin.Context) {
+		limit, ok := queryInt(c, opts.LimitParam)
+		if !ok {
+			abortWithError(c, http.StatusBadRequest, "invalid "+opts.LimitParam)
+			return
+		}
+		offset, ok := queryInt(c, opts.OffsetParam)
+		if !ok {
+			abortWithError(c, http.StatusBadRequest, "invalid "+opts.OffsetParam)
+			return
+		}
+
+		if limit == 0 {
+			limit = opts.DefaultLimit
+		}
+		if limit > opts.MaxLimit {
+			limit = opts.MaxLimit
+		}
+		if opts.MaxOffset > 0 && offset > opts.MaxOffset {
+			offset = opts.MaxOffset
+		}
+		c.Set(paginationKey, Page{Limit: limit, Offset: offset})
+		c.Next()
+	}
+}
+
+func PageFromContext(c *gin.Context) Page {
+	p, _ := c.Get(paginationKey)
+	page, _ := p.(Page)
+	return page
+}
+
+// queryInt parses a non-negative integer parameter; absent means 0.
+func queryInt(c *gin.Context, name string) (int, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	return n, err == nil && n >= 0
+}
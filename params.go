@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WithRouteParams makes Logger log the matched path parameters as param.<name>
+// fields, with the values of the redact names replaced. Catch-all parameters
+// (*path) are logged with their leading slash trimmed.
+func WithRouteParams(redact ...string) Option {
+	return func(o *options) {
+		o.routeParams = true
+		if o.redactParams == nil {
+			o.redactParams = map[string]bool{}
+		}
+		for _, name := range redact {
+			o.redactParams[name] = true
+		}
+	}
+}
+
+func (o *options) routeParamFields(c *gin.Context) []zap.Field {
+	zf := make([]zap.Field, 0, len(c.Params))
+	for _, p := range c.Params {
+		v := strings.TrimPrefix(p.Value, "/")
+		if o.redactParams[p.Key] {
+			v = redacted
+		}
+		zf = append(zf, zap.String("param."+p.Key, v))
+	}
+	return zf
+}
@@ -0,0 +1,13 @@
+package middleware
+
+import "go.uber.org/zap/zapcore"
+
+// WithPathLevel logs the api summary for path at level instead of Info.
+func WithPathLevel(path string, level zapcore.Level) Option {
+	return func(o *options) {
+		if o.pathLevels == nil {
+			o.pathLevels = map[string]zapcore.Level{}
+		}
+		o.pathLevels[path] = level
+	}
+}
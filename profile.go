@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"math/rand"
+	"reflect"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	profileMsg = "middleware_profile"
+	profileKey = "middleware_profile"
+)
+
+type profileFrame struct {
+	Handler  string `json:"handler"`
+	Duration string `json:"duration"`
+}
+
+type requestProfile struct {
+	frames []profileFrame
+	// children accumulates, per active wrapper, time spent in nested handlers.
+	children []time.Duration
+}
+
+// Profile wraps handlers so that, for a rate (0..1) fraction of requests, the
+// time spent in each one excluding the handlers it calls via c.Next() is
+// logged at Debug. It is a development aid:
+//
+//	r.Use(middleware.Profile(logger, 0.1, middleware.RequestID(), auth, middleware.Logger(logger))...)
+func Profile(logger *zap.Logger, rate float64, handlers ...gin.HandlerFunc) []gin.HandlerFunc {
+	wrapped := make([]gin.HandlerFunc, len(handlers))
+	for i, h := range handlers {
+		wrapped[i] = profileHandler(h)
+	}
+	if len(wrapped) == 0 {
+		return wrapped
+	}
+
+	first := wrapped[0]
+	wrapped[0] = func(c *gin.Context) {
+		if rand.Float64() >= rate {
+			first(c)
+			return
+		}
+		p := &requestProfile{}
+		c.Set(profileKey, p)
+		first(c)
+		// Run the rest here too, so the profile is complete even when the
+		// first handler does not call c.Next() itself.
+		c.Next()
+		logger.Debug(profileMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("method", c.Request.Method),
+			zap.String("path_uri", routePath(c)),
+			zap.Any("handlers", p.frames),
+		)
+	}
+	return wrapped
+}
+
+func profileHandler(h gin.HandlerFunc) gin.HandlerFunc {
+	name := runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+	return func(c *gin.Context) {
+		v, ok := c.Get(profileKey)
+		if !ok {
+			h(c)
+			return
+		}
+		p := v.(*requestProfile)
+		idx := len(p.frames)
+		p.frames = append(p.frames, profileFrame{Handler: name})
+		p.children = append(p.children, 0)
+		start := time.Now()
+		h(c)
+		elapsed := time.Since(start)
+		top := len(p.children) - 1
+		self := elapsed - p.children[top]
+		p.children = p.children[:top]
+		if top > 0 {
+			p.children[top-1] += elapsed
+		}
+		p.frames[idx].Duration = self.String()
+	}
+}
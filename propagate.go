@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID set by RequestID. It accepts
+// either the request context or the *gin.Context.
+func RequestIDFromContext(ctx context.Context) string {
+	ctx = requestContext(ctx)
+	xid, _ := ctx.Value(requestIDKey{}).(string)
+	return xid
+}
+
+// PropagateRequestID wraps base (http.DefaultTransport when nil) so outgoing
+// requests carry the X-Request-ID, the tenant header set by Tenant and the
+// baggage set by Baggage from their context. Headers the request already has
+// are left alone:
+//
+//	client := &http.Client{Transport: middleware.PropagateRequestID(nil)}
+//	req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, url, nil)
+//	client.Do(req)
+func PropagateRequestID(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+		set := map[string]string{}
+		if xid := RequestIDFromContext(ctx); xid != "" {
+			set[X_REQUEST_ID] = xid
+		}
+		if t, _ := ctx.Value(tenantKey{}).(tenant); t.id != "" {
+			set[t.header] = t.id
+		}
+		if b := BaggageFromContext(ctx); len(b) > 0 {
+			set[baggageHeader] = formatBaggage(b)
+		}
+		cloned := false
+		for k, v := range set {
+			if req.Header.Get(k) != "" {
+				continue
+			}
+			if !cloned {
+				req = req.Clone(ctx)
+				cloned = true
+			}
+			req.Header.Set(k, v)
+		}
+		return base.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
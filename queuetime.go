@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WithQueueTime makes Logger log queue_ms, the time between the load balancer
+// stamping X-Request-Start and Logger seeing the request. The header may be
+// "t=<unix time>" or a bare unix time in seconds, milliseconds, microseconds
+// or nanoseconds. Requests without a usable header get no field.
+func WithQueueTime() Option {
+	return func(o *options) {
+		o.queueTime = true
+	}
+}
+
+func queueTimeFields(c *gin.Context, start time.Time) []zap.Field {
+	at, ok := parseRequestStart(c.GetHeader("X-Request-Start"))
+	if !ok {
+		return nil
+	}
+	queue := start.Sub(at)
+	if queue < 0 {
+		// Clock skew between the balancer and us.
+		queue = 0
+	}
+	return []zap.Field{zap.Float64("queue_ms", float64(queue.Microseconds())/1000)}
+}
+
+func parseRequestStart(v string) (time.Time, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "t=")
+	if v == "" {
+		return time.Time{}, false
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		return time.Time{}, false
+	}
+	// Guess the unit from the magnitude; any of them is ~2001 onwards.
+	switch {
+	case f < 1e11:
+		f *= 1e9
+	case f < 1e14:
+		f *= 1e6
+	case f < 1e17:
+		f *= 1e3
+	}
+	return time.Unix(0, int64(f)), true
+}
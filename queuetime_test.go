@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseRequestStart(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	tests := []struct {
+		name string
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"seconds", strconv.FormatInt(at.Unix(), 10), at.Truncate(time.Second), true},
+		{"fractional seconds", "t=" + strconv.FormatInt(at.Unix(), 10) + ".5", at.Truncate(time.Second).Add(500 * time.Millisecond), true},
+		{"milliseconds", strconv.FormatInt(at.UnixMilli(), 10), at.Truncate(time.Millisecond), true},
+		{"microseconds", "t=" + strconv.FormatInt(at.UnixMicro(), 10), at.Truncate(time.Microsecond), true},
+		{"nanoseconds", strconv.FormatInt(at.UnixNano(), 10), at, true},
+		{"surrounding space", " t=" + strconv.FormatInt(at.Unix(), 10) + " ", at.Truncate(time.Second), true},
+		{"empty", "", time.Time{}, false},
+		{"prefix only", "t=", time.Time{}, false},
+		{"zero", "0", time.Time{}, false},
+		{"negative", "-1", time.Time{}, false},
+		{"garbage", "t=soon", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRequestStart(tt.in)
+			if ok != tt.ok {
+				t.Fatalf("parseRequestStart(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			}
+			// Float parsing loses sub-microsecond precision on nanosecond stamps.
+			if d := got.Sub(tt.want); d < -time.Microsecond || d > time.Microsecond {
+				t.Errorf("parseRequestStart(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoggerQueueTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		wantField bool
+		wantMin   float64
+	}{
+		{"no header", "", false, 0},
+		{"invalid header", "t=soon", false, 0},
+		{"queued", "t=" + strconv.FormatInt(time.Now().Add(-time.Second).UnixMicro(), 10), true, 1000},
+		{"clock skew", strconv.FormatInt(time.Now().Add(time.Hour).UnixMilli(), 10), true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			r := gin.New()
+			r.Use(Logger(logger, WithQueueTime()))
+			r.GET("/x", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Request-Start", tt.header)
+			}
+			serve(r, req)
+
+			got := summaries(logs)
+			if len(got) != 1 {
+				t.Fatalf("got %d api summaries, want 1", len(got))
+			}
+			v, ok := got[0].ContextMap()["queue_ms"]
+			if ok != tt.wantField {
+				t.Fatalf("queue_ms present = %v, want %v", ok, tt.wantField)
+			}
+			if !ok {
+				return
+			}
+			ms := v.(float64)
+			if ms < tt.wantMin || (tt.wantMin == 0 && ms != 0) {
+				t.Errorf("queue_ms = %v, want >= %v", ms, tt.wantMin)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	quotaExceededMsg = "quota_exceeded"
+	quotaErrorMsg    = "quota_store_error"
+)
+
+// QuotaStore counts requests per key over a window, e.g. backed by Redis
+// INCR with EXPIRE.
+type QuotaStore interface {
+	// Incr increments key and returns the new count and when the current
+	// window resets.
+	Incr(ctx context.Context, key string, window time.Duration) (count int64, reset time.Time, err error)
+}
+
+type QuotaOptions struct {
+	// Limit is required and must be positive; Quota panics otherwise rather
+	// than guess a default.
+	Limit int64
+	// Window defaults to one minute.
+	Window time.Duration
+	// Key identifies the quota holder; defaults to the X-API-Key header.
+	// Requests with an empty key are not counted.
+	Key func(c *gin.Context) string
+}
+
+// Quota aborts with 429 once a key has used opts.Limit requests in the current
+// window, and sets X-RateLimit-* headers on every counted response. Store
+// errors are logged and the request is let through. It panics if opts.Limit
+// is not positive.
+func Quota(logger *zap.Logger, store QuotaStore, opts QuotaOptions) gin.HandlerFunc {
+	if opts.Limit <= 0 {
+		panic("middleware: QuotaOptions.Limit must be positive")
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Minute
+	}
+	if opts.Key == nil {
+		opts.Key = func(c *gin.Context) string { return c.GetHeader("X-API-Key") }
+	}
+	limit := strconv.FormatInt(opts.Limit, 10)
+	return func(c *gin.Context) {
+		key := opts.Key(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		count, reset, err := store.Incr(c.Request.Context(), key, opts.Window)
+		if err != nil {
+			logger.Error(quotaErrorMsg, zap.String("xid", getRequestID(c)), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		remaining := opts.Limit - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header("X-RateLimit-Limit", limit)
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		if count > opts.Limit {
+			logger.Warn(quotaExceededMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("key", key),
+				zap.String("path_uri", routePath(c)),
+				zap.Int64("count", count),
+				zap.Int64("limit", opts.Limit),
+			)
+			c.Header("Retry-After", strconv.Itoa(int(time.Until(reset).Seconds())+1))
+			abortWithError(c, http.StatusTooManyRequests, "quota exceeded")
+			return
+		}
+		c.Next()
+	}
+}
+
+// MemoryQuotaStore is an in-process QuotaStore for tests and single-instance
+// deployments. Windows start at a key's first request, and expired ones are
+// swept about once a window, so memory is bounded by the keys seen per window.
+type MemoryQuotaStore struct {
+	mu        sync.Mutex
+	windows   map[string]*quotaWindow
+	nextSweep time.Time
+}
+
+type quotaWindow struct {
+	count int64
+	reset time.Time
+}
+
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{windows: map[string]*quotaWindow{}}
+}
+
+func (s *MemoryQuotaStore) Incr(_ context.Context, key string, window time.Duration) (int64, time.Time, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !now.Before(s.nextSweep) {
+		for k, w := range s.windows {
+			if !now.Before(w.reset) {
+				delete(s.windows, k)
+			}
+		}
+		s.nextSweep = now.Add(window)
+	}
+	w := s.windows[key]
+	if w == nil || !now.Before(w.reset) {
+		w = &quotaWindow{reset: now.Add(window)}
+		s.windows[key] = w
+	}
+	w.count++
+	return w.count, w.reset, nil
+}
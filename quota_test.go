@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestQuota(t *testing.T) {
+	logger, logs := observedLogger(zapcore.DebugLevel)
+	r := gin.New()
+	r.Use(Quota(logger, NewMemoryQuotaStore(), QuotaOptions{Limit: 2}))
+	r.GET("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name          string
+		key           string
+		wantStatus    int
+		wantRemaining string
+	}{
+		{"first", "k1", http.StatusOK, "1"},
+		{"second", "k1", http.StatusOK, "0"},
+		{"over limit", "k1", http.StatusTooManyRequests, "0"},
+		{"other key", "k2", http.StatusOK, "1"},
+		{"no key", "", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/x", nil)
+		if tt.key != "" {
+			req.Header.Set("X-API-Key", tt.key)
+		}
+		w := serve(r, req)
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != tt.wantRemaining {
+			t.Errorf("%s: X-RateLimit-Remaining = %q, want %q", tt.name, got, tt.wantRemaining)
+		}
+		if tt.wantStatus == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+			t.Errorf("%s: no Retry-After", tt.name)
+		}
+	}
+	if n := logs.FilterMessage(quotaExceededMsg).Len(); n != 1 {
+		t.Errorf("logged %d exceeded quotas, want 1", n)
+	}
+}
+
+func TestQuotaOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      QuotaOptions
+		wantPanic bool
+	}{
+		{"valid", QuotaOptions{Limit: 1, Window: time.Second}, false},
+		{"default window", QuotaOptions{Limit: 1}, false},
+		{"zero limit", QuotaOptions{}, true},
+		{"negative limit", QuotaOptions{Limit: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if panicked := recover() != nil; panicked != tt.wantPanic {
+					t.Errorf("panicked = %v, want %v", panicked, tt.wantPanic)
+				}
+			}()
+			logger, _ := observedLogger(zapcore.DebugLevel)
+			Quota(logger, NewMemoryQuotaStore(), tt.opts)
+		})
+	}
+}
+
+func TestMemoryQuotaStoreWindows(t *testing.T) {
+	s := NewMemoryQuotaStore()
+	ctx := context.Background()
+	const window = 20 * time.Millisecond
+
+	for i := int64(1); i <= 3; i++ {
+		if n, _, _ := s.Incr(ctx, "a", window); n != i {
+			t.Fatalf("count = %d, want %d", n, i)
+		}
+	}
+	s.Incr(ctx, "b", window)
+	time.Sleep(2 * window)
+
+	// A new window restarts the count, and expired keys are swept.
+	if n, _, _ := s.Incr(ctx, "a", window); n != 1 {
+		t.Errorf("count after window = %d, want 1", n)
+	}
+	if _, ok := s.windows["b"]; ok {
+		t.Error("expired window for b was not swept")
+	}
+}
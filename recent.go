@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecentRequest struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"xid"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path_uri"`
+	Status    int       `json:"status"`
+	Latency   string    `json:"latency"`
+}
+
+// RecentRequests keeps the last N requests in a fixed-size ring.
+type RecentRequests struct {
+	mu      sync.Mutex
+	entries []RecentRequest
+	next    int
+	full    bool
+}
+
+func NewRecentRequests(size int) *RecentRequests {
+	if size < 1 {
+		size = 1
+	}
+	return &RecentRequests{entries: make([]RecentRequest, size)}
+}
+
+func (r *RecentRequests) Record() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		defer func() {
+			// Panicking requests are the ones most worth inspecting.
+			rec := recover()
+			r.add(RecentRequest{
+				Time:      start,
+				RequestID: getRequestID(c),
+				Method:    c.Request.Method,
+				Path:      routePath(c),
+				Status:    finalStatus(c, rec),
+				Latency:   time.Since(start).String(),
+			})
+			if rec != nil {
+				panic(rec)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// Handler responds with the recorded requests as JSON, newest first.
+func (r *RecentRequests) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, r.Snapshot())
+	}
+}
+
+func (r *RecentRequests) Snapshot() []RecentRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.entries)
+	}
+	out := make([]RecentRequest, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, r.entries[(r.next-i+len(r.entries))%len(r.entries)])
+	}
+	return out
+}
+
+func (r *RecentRequests) add(e RecentRequest) {
+	r.mu.Lock()
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+	r.mu.Unlock()
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecentRequestsRecord(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		wantStatus int
+	}{
+		{"ok", func(c *gin.Context) { c.Status(http.StatusCreated) }, http.StatusCreated},
+		{"panic", func(c *gin.Context) { panic("boom") }, http.StatusInternalServerError},
+		{"panic after write", func(c *gin.Context) { c.String(http.StatusAccepted, "partial"); panic("boom") }, http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recent := NewRecentRequests(4)
+			r := gin.New()
+			r.Use(gin.Recovery(), recent.Record())
+			r.GET("/users/:id", tt.handler)
+
+			serve(r, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+
+			got := recent.Snapshot()
+			if len(got) != 1 {
+				t.Fatalf("recorded %d requests, want 1", len(got))
+			}
+			if got[0].Status != tt.wantStatus || got[0].Path != "/users/:id" {
+				t.Errorf("recorded %+v, want status %d on /users/:id", got[0], tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRecentRequestsRing(t *testing.T) {
+	recent := NewRecentRequests(2)
+	r := gin.New()
+	r.Use(recent.Record())
+	r.GET("/:n", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, id := range []string{"a", "b", "c"} {
+		req := httptest.NewRequest(http.MethodGet, "/"+id, nil)
+		req.Header.Set(X_REQUEST_ID, id)
+		serve(r, req)
+	}
+
+	got := recent.Snapshot()
+	if len(got) != 2 || got[0].RequestID != "c" || got[1].RequestID != "b" {
+		t.Errorf("snapshot = %+v, want c then b", got)
+	}
+}
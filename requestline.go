@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const redacted = "REDACTED"
+
+// WithRequestLine adds an access-log style request_line field
+// ("GET /users/123?x=1 HTTP/1.1") to the api summary.
+func WithRequestLine() Option {
+	return func(o *options) {
+		o.requestLine = true
+	}
+}
+
+// WithRedactQuery replaces the values of the named query parameters with
+// REDACTED wherever the raw query is logged.
+func WithRedactQuery(params ...string) Option {
+	return func(o *options) {
+		if o.redactQuery == nil {
+			o.redactQuery = map[string]bool{}
+		}
+		for _, p := range params {
+			o.redactQuery[p] = true
+		}
+	}
+}
+
+func (o *options) requestLineOf(c *gin.Context) string {
+	uri := c.Request.RequestURI
+	if uri == "" {
+		uri = c.Request.URL.RequestURI()
+	}
+	return c.Request.Method + " " + o.redactURI(uri) + " " + c.Request.Proto
+}
+
+func (o *options) redactURI(uri string) string {
+	path, query, ok := strings.Cut(uri, "?")
+	if !ok || len(o.redactQuery) == 0 {
+		return uri
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && o.redactQuery[name] {
+			pairs[i] = key + "=" + redacted
+		}
+	}
+	return path + "?" + strings.Join(pairs, "&")
+}
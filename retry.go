@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const retryMsg = "retry"
+
+// Retry runs handler up to attempts times for GET, HEAD and OPTIONS requests
+// while it responds with a 5xx, waiting backoff between attempts. Each attempt
+// gets a fresh buffered response and the original request body, followed by
+// any error reading it; only the final response reaches the client. Other
+// methods run handler once, unbuffered.
+//
+// gin cannot rewind c.Next(), so Retry wraps the handler it retries:
+//
+//	r.GET("/flaky", middleware.Retry(logger, 3, 100*time.Millisecond, handler))
+func Retry(logger *zap.Logger, attempts int, backoff time.Duration, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			handler(c)
+			return
+		}
+
+		reqBody := c.Request.Body
+		body, err := io.ReadAll(reqBody)
+		orig := c.Writer
+		// Put orig back even if handler panics, so an outer Recovery's 500
+		// is not lost in an attempt's buffer.
+		defer func() { c.Writer = orig }()
+		var w *bufferedResponseWriter
+	loop:
+		for attempt := 1; ; attempt++ {
+			// Every attempt also gets the read error, such as a
+			// *http.MaxBytesError, after the bytes that were read.
+			c.Request.Body = replayBody(body, err, reqBody)
+			w = newBufferedResponseWriter(orig)
+			c.Writer = w
+			handler(c)
+			if w.status < 500 || attempt >= attempts {
+				break
+			}
+
+			logger.Warn(retryMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("method", c.Request.Method),
+				zap.String("path_uri", routePath(c)),
+				zap.Int("status", w.status),
+				zap.Int("attempt", attempt),
+			)
+			select {
+			case <-c.Request.Context().Done():
+				break loop
+			case <-time.After(backoff):
+			}
+		}
+		c.Writer = orig
+		w.flush()
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		failures     int // attempts answering 503 before one succeeds
+		wantCalls    int
+		wantStatus   int
+		wantRetryLog int
+	}{
+		{"first try", http.MethodGet, 0, 1, http.StatusOK, 0},
+		{"recovers", http.MethodGet, 2, 3, http.StatusOK, 2},
+		{"gives up", http.MethodGet, 5, 3, http.StatusServiceUnavailable, 2},
+		{"head retried", http.MethodHead, 1, 2, http.StatusOK, 1},
+		{"post not retried", http.MethodPost, 1, 1, http.StatusServiceUnavailable, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			calls := 0
+			handler := func(c *gin.Context) {
+				calls++
+				body, _ := io.ReadAll(c.Request.Body)
+				if string(body) != "payload" {
+					t.Errorf("attempt %d read body %q", calls, body)
+				}
+				if calls <= tt.failures {
+					c.String(http.StatusServiceUnavailable, "attempt "+strconv.Itoa(calls))
+					return
+				}
+				c.String(http.StatusOK, "attempt "+strconv.Itoa(calls))
+			}
+			r := gin.New()
+			r.Handle(tt.method, "/x", Retry(logger, 3, 0, handler))
+
+			w := serve(r, httptest.NewRequest(tt.method, "/x", strings.NewReader("payload")))
+
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			// Only the final attempt reaches the client.
+			if want := "attempt " + strconv.Itoa(calls); tt.method != http.MethodHead && w.Body.String() != want {
+				t.Errorf("body = %q, want %q", w.Body.String(), want)
+			}
+			if n := logs.FilterMessage(retryMsg).Len(); n != tt.wantRetryLog {
+				t.Errorf("logged %d retries, want %d", n, tt.wantRetryLog)
+			}
+		})
+	}
+}
+
+// A body read error must reach every attempt, not turn into a short body.
+func TestRetryBodyReadError(t *testing.T) {
+	logger, _ := observedLogger(zapcore.DebugLevel)
+	var errs []error
+	r := gin.New()
+	r.GET("/x", func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 4)
+		c.Next()
+	}, Retry(logger, 2, 0, func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if string(body) != "payl" {
+			t.Errorf("attempt read %q, want the first 4 bytes", body)
+		}
+		errs = append(errs, err)
+		c.Status(http.StatusServiceUnavailable)
+	}))
+
+	serve(r, httptest.NewRequest(http.MethodGet, "/x", strings.NewReader("payload")))
+
+	if len(errs) != 2 {
+		t.Fatalf("ran %d attempts, want 2", len(errs))
+	}
+	for i, err := range errs {
+		var tooLarge *http.MaxBytesError
+		if !errors.As(err, &tooLarge) {
+			t.Errorf("attempt %d read error = %v, want *http.MaxBytesError", i+1, err)
+		}
+	}
+}
+
+func TestRetryPanic(t *testing.T) {
+	logger, _ := observedLogger(zapcore.DebugLevel)
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.GET("/x", Retry(logger, 3, 0, func(c *gin.Context) { panic("boom") }))
+
+	if w := serve(r, httptest.NewRequest(http.MethodGet, "/x", nil)); w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RouteMeta holds metadata registered per route, keyed by method and full path.
+type RouteMeta struct {
+	mu     sync.RWMutex
+	routes map[string]map[string]string
+}
+
+func NewRouteMeta() *RouteMeta {
+	return &RouteMeta{routes: map[string]map[string]string{}}
+}
+
+// Handle registers the route on g and records meta for it.
+func (m *RouteMeta) Handle(g *gin.RouterGroup, method, relativePath string, meta map[string]string, handlers ...gin.HandlerFunc) gin.IRoutes {
+	m.Set(method, joinPaths(g.BasePath(), relativePath), meta)
+	return g.Handle(method, relativePath, handlers...)
+}
+
+func (m *RouteMeta) Set(method, fullPath string, meta map[string]string) {
+	cp := make(map[string]string, len(meta))
+	for k, v := range meta {
+		cp[k] = v
+	}
+	m.mu.Lock()
+	m.routes[method+" "+fullPath] = cp
+	m.mu.Unlock()
+}
+
+func (m *RouteMeta) Get(method, fullPath string) map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.routes[method+" "+fullPath]
+}
+
+func (m *RouteMeta) fields(c *gin.Context) []zap.Field {
+	meta := m.Get(c.Request.Method, c.FullPath())
+	if len(meta) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	zf := make([]zap.Field, 0, len(keys))
+	for _, k := range keys {
+		zf = append(zf, zap.String("route."+k, meta[k]))
+	}
+	return zf
+}
+
+// WithRouteMeta logs the matched route's metadata as route.<key> fields on the
+// api summary.
+func WithRouteMeta(m *RouteMeta) Option {
+	return func(o *options) {
+		o.routeMeta = m
+	}
+}
+
+// joinPaths mirrors gin's unexported helper so keys match c.FullPath().
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	finalPath := path.Join(absolutePath, relativePath)
+	if relativePath[len(relativePath)-1] == '/' && finalPath[len(finalPath)-1] != '/' {
+		return finalPath + "/"
+	}
+	return finalPath
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerRouteMeta(t *testing.T) {
+	logger, logs := observedLogger(zapcore.DebugLevel)
+	meta := NewRouteMeta()
+	r := gin.New()
+	r.Use(Logger(logger, WithRouteMeta(meta)))
+	api := r.Group("/api")
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	meta.Handle(api, http.MethodGet, "/users/:id", map[string]string{"owner": "identity", "tier": "1"}, ok)
+	meta.Handle(api, http.MethodPost, "/users/:id", map[string]string{"owner": "billing"}, ok)
+	meta.Handle(api, http.MethodGet, "/dir/", map[string]string{"owner": "files"}, ok)
+	api.GET("/plain", ok)
+
+	tests := []struct {
+		method, target string
+		want           map[string]interface{}
+	}{
+		{http.MethodGet, "/api/users/7", map[string]interface{}{"route.owner": "identity", "route.tier": "1"}},
+		{http.MethodPost, "/api/users/7", map[string]interface{}{"route.owner": "billing"}},
+		{http.MethodGet, "/api/dir/", map[string]interface{}{"route.owner": "files"}},
+		{http.MethodGet, "/api/plain", map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		logs.TakeAll()
+		serve(r, httptest.NewRequest(tt.method, tt.target, nil))
+
+		got := summaries(logs)
+		if len(got) != 1 {
+			t.Fatalf("%s %s: got %d api summaries, want 1", tt.method, tt.target, len(got))
+		}
+		fields := got[0].ContextMap()
+		for k, v := range tt.want {
+			if fields[k] != v {
+				t.Errorf("%s %s: %s = %v, want %v", tt.method, tt.target, k, fields[k], v)
+			}
+		}
+		for k := range fields {
+			if _, ok := tt.want[k]; !ok && strings.HasPrefix(k, "route.") {
+				t.Errorf("%s %s: unexpected %s", tt.method, tt.target, k)
+			}
+		}
+	}
+}
+
+func TestRouteMetaSetCopies(t *testing.T) {
+	m := NewRouteMeta()
+	meta := map[string]string{"owner": "a"}
+	m.Set(http.MethodGet, "/x", meta)
+	meta["owner"] = "b"
+	if got := m.Get(http.MethodGet, "/x")["owner"]; got != "a" {
+		t.Errorf("owner = %q after mutating the caller's map, want %q", got, "a")
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const schemaDriftMsg = "schema_drift"
+
+// SchemaRecorder tracks the JSON shape (keys and types, not values) of
+// responses per method, route and status.
+type SchemaRecorder struct {
+	mu       sync.Mutex
+	baseline map[string]string
+}
+
+// NewSchemaRecorder starts from baseline, as saved from Snapshot. Routes
+// missing from it take their first observed shape as the baseline.
+func NewSchemaRecorder(baseline map[string]string) *SchemaRecorder {
+	b := make(map[string]string, len(baseline))
+	for k, v := range baseline {
+		b[k] = v
+	}
+	return &SchemaRecorder{baseline: b}
+}
+
+func (s *SchemaRecorder) Snapshot() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]string, len(s.baseline))
+	for k, v := range s.baseline {
+		out[k] = v
+	}
+	return out
+}
+
+// WithSchemaDrift makes ResponseLogger compare JSON response shapes against
+// s and warn with schema_drift when they diverge. Meant for debug and staging.
+func WithSchemaDrift(s *SchemaRecorder) Option {
+	return func(o *options) {
+		o.schemaDrift = s
+	}
+}
+
+func (s *SchemaRecorder) check(logger *zap.Logger, c *gin.Context, body []byte) {
+	if !isJSONContentType(c.Writer.Header().Get("Content-Type")) {
+		return
+	}
+	var v interface{}
+	if json.Unmarshal(body, &v) != nil {
+		return
+	}
+	key := c.Request.Method + " " + c.FullPath() + " " + strconv.Itoa(c.Writer.Status())
+	shape := jsonShape(v)
+
+	s.mu.Lock()
+	expected, ok := s.baseline[key]
+	if !ok {
+		s.baseline[key] = shape
+	}
+	s.mu.Unlock()
+
+	if ok && expected != shape {
+		logger.Warn(schemaDriftMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("route", key),
+			zap.String("expected", expected),
+			zap.String("actual", shape),
+		)
+	}
+}
+
+// jsonShape renders the structure of v with sorted keys; arrays take the shape
+// of their first element.
+func jsonShape(v interface{}) string {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = strconv.Quote(k) + ":" + jsonShape(t[k])
+		}
+		return "{" + strings.Join(parts, ",") + "}"
+	case []interface{}:
+		if len(t) == 0 {
+			return "[]"
+		}
+		return "[" + jsonShape(t[0]) + "]"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	default:
+		return "null"
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"container/list"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const outOfOrderMsg = "sequence_out_of_order"
+
+type SequenceOptions struct {
+	// KeyHeader identifies the client; defaults to "X-Client-ID". Requests
+	// without it are not checked.
+	KeyHeader string
+	// SequenceHeader defaults to "X-Sequence".
+	SequenceHeader string
+	// MaxClients bounds the tracked clients; least recently seen are evicted.
+	// Defaults to 10000.
+	MaxClients int
+}
+
+// Sequence rejects with 409 requests whose sequence number is not strictly
+// greater than the last one accepted from the same client, and with 400 those
+// carrying a malformed sequence.
+func Sequence(logger *zap.Logger, opts SequenceOptions) gin.HandlerFunc {
+	if opts.KeyHeader == "" {
+		opts.KeyHeader = "X-Client-ID"
+	}
+	if opts.SequenceHeader == "" {
+		opts.SequenceHeader = "X-Sequence"
+	}
+	if opts.MaxClients <= 0 {
+		opts.MaxClients = 10000
+	}
+	seqs := &sequenceLRU{max: opts.MaxClients, order: list.New(), items: map[string]*list.Element{}}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(opts.KeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		seq, err := strconv.ParseUint(c.GetHeader(opts.SequenceHeader), 10, 64)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, "invalid "+opts.SequenceHeader)
+			return
+		}
+		if last, ok := seqs.advance(key, seq); !ok {
+			logger.Warn(outOfOrderMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("client", key),
+				zap.Uint64("expected_greater_than", last),
+				zap.Uint64("received", seq),
+			)
+			abortWithError(c, http.StatusConflict, "sequence out of order")
+			return
+		}
+		c.Next()
+	}
+}
+
+type sequenceLRU struct {
+	mu    sync.Mutex
+	max   int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type sequenceEntry struct {
+	key  string
+	last uint64
+}
+
+// advance records seq for key when it is greater than the last one seen. It
+// returns the previous sequence and whether seq was accepted.
+func (l *sequenceLRU) advance(key string, seq uint64) (uint64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if el, ok := l.items[key]; ok {
+		e := el.Value.(*sequenceEntry)
+		l.order.MoveToFront(el)
+		prev := e.last
+		if seq <= prev {
+			return prev, false
+		}
+		e.last = seq
+		return prev, true
+	}
+	l.items[key] = l.order.PushFront(&sequenceEntry{key: key, last: seq})
+	if l.order.Len() > l.max {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*sequenceEntry).key)
+	}
+	return 0, true
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSequence(t *testing.T) {
+	logger, logs := observedLogger(zapcore.WarnLevel)
+	r := gin.New()
+	r.Use(Sequence(logger, SequenceOptions{MaxClients: 2}))
+	r.POST("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// The steps share one middleware, so each depends on those before it.
+	steps := []struct {
+		name   string
+		client string
+		seq    string
+		want   int
+	}{
+		{"no client", "", "", http.StatusOK},
+		{"first", "a", "5", http.StatusOK},
+		{"next", "a", "6", http.StatusOK},
+		{"gap allowed", "a", "9", http.StatusOK},
+		{"replayed", "a", "9", http.StatusConflict},
+		{"out of order", "a", "7", http.StatusConflict},
+		{"rejected not recorded", "a", "10", http.StatusOK},
+		{"missing sequence", "a", "", http.StatusBadRequest},
+		{"malformed sequence", "a", "11x", http.StatusBadRequest},
+		{"negative sequence", "a", "-1", http.StatusBadRequest},
+		{"other client", "b", "1", http.StatusOK},
+		{"other client out of order", "b", "0", http.StatusConflict},
+		// A third client evicts a, the least recently seen.
+		{"evicts oldest", "c", "1", http.StatusOK},
+		{"evicted restarts", "a", "1", http.StatusOK},
+	}
+	for _, st := range steps {
+		req := httptest.NewRequest(http.MethodPost, "/x", nil)
+		if st.client != "" {
+			req.Header.Set("X-Client-ID", st.client)
+			req.Header.Set("X-Sequence", st.seq)
+		}
+		if w := serve(r, req); w.Code != st.want {
+			t.Fatalf("%s: status = %d, want %d", st.name, w.Code, st.want)
+		}
+	}
+
+	entries := logs.FilterMessage(outOfOrderMsg).All()
+	if len(entries) != 3 {
+		t.Fatalf("logs = %v, want three %s", summaries(logs), outOfOrderMsg)
+	}
+	fields := entries[1].ContextMap()
+	if fields["client"] != "a" || fields["expected_greater_than"] != uint64(9) || fields["received"] != uint64(7) {
+		t.Errorf("fields = %v", fields)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServerTiming sets X-Request-Received to the RFC 3339 arrival time and
+// Server-Timing: app;dur=<ms> to the time spent before the response headers
+// went out, so browsers show it in devtools. Place it first, next to Logger,
+// so both measure from the same point.
+func ServerTiming() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Header("X-Request-Received", start.UTC().Format(time.RFC3339Nano))
+		w := &serverTimingWriter{ResponseWriter: c.Writer, start: start}
+		c.Writer = w
+		c.Next()
+		if !w.Written() {
+			w.WriteHeaderNow()
+		}
+	}
+}
+
+// serverTimingWriter adds the Server-Timing header right before the headers
+// are sent, which is the last moment it can still be set.
+type serverTimingWriter struct {
+	gin.ResponseWriter
+	start time.Time
+	set   bool
+}
+
+func (w *serverTimingWriter) setHeader() {
+	if w.set || w.ResponseWriter.Written() {
+		return
+	}
+	w.set = true
+	ms := float64(time.Since(w.start).Microseconds()) / 1000
+	w.Header().Add("Server-Timing", fmt.Sprintf("app;dur=%.3f", ms))
+}
+
+func (w *serverTimingWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *serverTimingWriter) Write(b []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *serverTimingWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *serverTimingWriter) Flush() {
+	w.setHeader()
+	w.ResponseWriter.Flush()
+}
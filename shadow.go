@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ShadowDiff is how a candidate config's api summary would differ from the
+// one actually logged. Field lists hold field keys and are sorted.
+type ShadowDiff struct {
+	XID             string
+	Path            string
+	Logged          bool
+	CandidateLogged bool
+	Level           zapcore.Level
+	CandidateLevel  zapcore.Level
+	Added           []string
+	Removed         []string
+	Changed         []string
+}
+
+// WithShadow makes Logger also build each api summary under candidate, log
+// only the current one, and hand sink a ShadowDiff whenever they differ, to
+// try redaction, level or throttling changes before rolling them out.
+// Options that act before the handlers run, such as WithCombinedLog or
+// WithDBCalls, are taken from the current config only. Nothing about the
+// candidate is logged except through sink.
+func WithShadow(sink func(ShadowDiff), candidate ...Option) Option {
+	cand := newOptions(candidate)
+	// The candidate gets its own copy of stateful options, so evaluating it
+	// can never change what the current config counts or logs.
+	if t := cand.errorThrottle; t != nil {
+		cand.errorThrottle = &errorThrottle{opts: t.opts}
+	}
+	if b := cand.sizeBuckets; b != nil {
+		cand.sizeBuckets = NewSizeBuckets(b.bounds...)
+	}
+	s := &shadow{candidate: cand, sink: sink}
+	return func(o *options) {
+		o.shadow = s
+	}
+}
+
+type shadow struct {
+	candidate *options
+	sink      func(ShadowDiff)
+}
+
+func (s *shadow) compare(logger *zap.Logger, c *gin.Context, start, end time.Time, status int, extra []zap.Field, level zapcore.Level, zf []zap.Field, ok bool) {
+	// A no-op logger keeps the candidate's throttle from logging for real.
+	cLevel, cFields, cOK := s.candidate.summary(zap.NewNop(), c, start, end, status, extra)
+	d := ShadowDiff{
+		XID:             getRequestID(c),
+		Path:            c.Request.URL.Path,
+		Logged:          ok && logger.Core().Enabled(level),
+		CandidateLogged: cOK && logger.Core().Enabled(cLevel),
+		Level:           level,
+		CandidateLevel:  cLevel,
+	}
+
+	cur, cand := fieldValues(zf), fieldValues(cFields)
+	for k, v := range cur {
+		cv, found := cand[k]
+		switch {
+		case !found:
+			d.Removed = append(d.Removed, k)
+		case cv != v:
+			d.Changed = append(d.Changed, k)
+		}
+	}
+	for k := range cand {
+		if _, found := cur[k]; !found {
+			d.Added = append(d.Added, k)
+		}
+	}
+	if d.Logged == d.CandidateLogged && level == cLevel && d.Added == nil && d.Removed == nil && d.Changed == nil {
+		return
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Changed)
+	s.sink(d)
+}
+
+func fieldValues(zf []zap.Field) map[string]string {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range zf {
+		f.AddTo(enc)
+	}
+	values := make(map[string]string, len(enc.Fields))
+	for k, v := range enc.Fields {
+		values[k] = fmt.Sprint(v)
+	}
+	return values
+}
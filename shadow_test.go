@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerShadow(t *testing.T) {
+	tests := []struct {
+		name      string
+		candidate []Option
+		want      *ShadowDiff
+	}{
+		{"same config", nil, nil},
+		{"level", []Option{WithPathLevel("/x", zapcore.WarnLevel)},
+			&ShadowDiff{Logged: true, CandidateLogged: true, Level: zapcore.InfoLevel, CandidateLevel: zapcore.WarnLevel}},
+		{"added field", []Option{WithQueueTime()},
+			&ShadowDiff{Logged: true, CandidateLogged: true, Added: []string{"queue_ms"}}},
+		{"sampled out", []Option{WithLogSampleRate(0)},
+			&ShadowDiff{Logged: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diffs []ShadowDiff
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			r := gin.New()
+			r.Use(Logger(logger, WithShadow(func(d ShadowDiff) { diffs = append(diffs, d) }, tt.candidate...)))
+			r.GET("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			req.Header.Set(X_REQUEST_ID, "xid-1")
+			req.Header.Set("X-Request-Start", strconv.FormatInt(time.Now().UnixMilli(), 10))
+			serve(r, req)
+
+			if n := len(summaries(logs)); n != 1 {
+				t.Errorf("got %d api summaries, want 1", n)
+			}
+			if tt.want == nil {
+				if len(diffs) != 0 {
+					t.Errorf("diffs = %+v, want none", diffs)
+				}
+				return
+			}
+			if len(diffs) != 1 {
+				t.Fatalf("got %d diffs, want 1", len(diffs))
+			}
+			want := *tt.want
+			want.XID, want.Path = "xid-1", "/x"
+			if !reflect.DeepEqual(diffs[0], want) {
+				t.Errorf("diff = %+v, want %+v", diffs[0], want)
+			}
+		})
+	}
+}
+
+// A throttling candidate must not log its suppressed counts for real. It
+// would log errors 1 and 2 and suppress 3 and 4.
+func TestLoggerShadowThrottleIsolated(t *testing.T) {
+	var diffs []ShadowDiff
+	logger, logs := observedLogger(zapcore.DebugLevel)
+	r := gin.New()
+	r.Use(Logger(logger,
+		WithOutcomeClassifier(StatusOutcome),
+		WithShadow(func(d ShadowDiff) { diffs = append(diffs, d) },
+			WithOutcomeClassifier(StatusOutcome),
+			WithErrorThrottle(ThrottleOptions{Threshold: 1, Interval: 20 * time.Millisecond, SampleEvery: 1000}),
+		),
+	))
+	r.GET("/x", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	for i := 0; i < 4; i++ {
+		serve(r, httptest.NewRequest(http.MethodGet, "/x", nil))
+	}
+	time.Sleep(60 * time.Millisecond)
+
+	if n := len(summaries(logs)); n != 4 {
+		t.Errorf("got %d api summaries, want 4", n)
+	}
+	if n := logs.FilterMessage(errorsSuppressedMsg).Len(); n != 0 {
+		t.Errorf("candidate logged %d suppressed counts", n)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("got %d diffs, want 2", len(diffs))
+	}
+	for _, d := range diffs {
+		if !d.Logged || d.CandidateLogged {
+			t.Errorf("diff = %+v, want logged only by the current config", d)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const signatureMsg = "signature_verification"
+
+type SignatureOptions struct {
+	// Header carrying the signature, e.g. "X-Hub-Signature-256".
+	Header string
+	// Prefix stripped from the header value, e.g. "sha256=".
+	Prefix string
+	Secret []byte
+	// Hash defaults to sha256.New.
+	Hash func() hash.Hash
+	// Base64 decodes the signature as base64 instead of hex.
+	Base64 bool
+}
+
+// VerifySignature aborts with 401 unless the signature header matches the
+// HMAC of the raw body. The body is restored for later middleware and the
+// handler.
+func VerifySignature(logger *zap.Logger, opts SignatureOptions) gin.HandlerFunc {
+	if opts.Hash == nil {
+		opts.Hash = sha256.New
+	}
+	return func(c *gin.Context) {
+		body := readRequestBody(c)
+		ok := validSignature(c.GetHeader(opts.Header), body, opts)
+		zf := []zap.Field{
+			zap.String("xid", getRequestID(c)),
+			zap.String("method", c.Request.Method),
+			zap.String("path_uri", routePath(c)),
+			zap.Bool("signature_valid", ok),
+		}
+		if !ok {
+			logger.Warn(signatureMsg, zf...)
+			abortWithError(c, http.StatusUnauthorized, "invalid signature")
+			return
+		}
+		logger.Debug(signatureMsg, zf...)
+		c.Next()
+	}
+}
+
+func validSignature(header string, body []byte, opts SignatureOptions) bool {
+	raw, ok := strings.CutPrefix(header, opts.Prefix)
+	if !ok || raw == "" {
+		return false
+	}
+	var sig []byte
+	var err error
+	if opts.Base64 {
+		sig, err = base64.StdEncoding.DecodeString(raw)
+	} else {
+		sig, err = hex.DecodeString(raw)
+	}
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(opts.Hash, opts.Secret)
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+type flightResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// SingleFlight coalesces concurrent identical GET/HEAD requests so the handler
+// runs once and every waiter receives the same status and body. Waiters get
+// only the headers the handlers after SingleFlight added, merged into their
+// own, and never Set-Cookie, so per-caller headers set by outer middleware
+// stay with their caller. key defaults to method+path+query; since that
+// ignores headers, only use the default on responses that do not vary per
+// caller.
+func SingleFlight(key func(c *gin.Context) string) gin.HandlerFunc {
+	if key == nil {
+		key = defaultFlightKey
+	}
+	var group singleflight.Group
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		leader := false
+		v, _, _ := group.Do(key(c), func() (interface{}, error) {
+			leader = true
+			before := c.Writer.Header().Clone()
+			w := &responseBodyWriter{body: &bytes.Buffer{}, ResponseWriter: c.Writer}
+			c.Writer = w
+			defer func() { c.Writer = w.ResponseWriter }()
+			c.Next()
+			return &flightResponse{
+				status: w.Status(),
+				header: addedHeaders(before, w.Header()),
+				body:   w.body.Bytes(),
+			}, nil
+		})
+		if leader {
+			return
+		}
+
+		res := v.(*flightResponse)
+		h := c.Writer.Header()
+		for k, vs := range res.header {
+			for _, v := range vs {
+				if !containsValue(h[k], v) {
+					h[k] = append(h[k], v)
+				}
+			}
+		}
+		c.Writer.WriteHeader(res.status)
+		c.Writer.Write(res.body)
+		c.Abort()
+	}
+}
+
+func defaultFlightKey(c *gin.Context) string {
+	return c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.RawQuery
+}
+
+// addedHeaders returns the header values in after that are not in before,
+// leaving out Set-Cookie, which is always per caller.
+func addedHeaders(before, after http.Header) http.Header {
+	added := http.Header{}
+	for k, vs := range after {
+		if k == "Set-Cookie" {
+			continue
+		}
+		for _, v := range vs {
+			if !containsValue(before[k], v) {
+				added[k] = append(added[k], v)
+			}
+		}
+	}
+	return added
+}
+
+func containsValue(vs []string, v string) bool {
+	for _, x := range vs {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
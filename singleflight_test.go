@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSingleFlightPerCallerHeaders(t *testing.T) {
+	const callers = 3
+	var calls atomic.Int32
+	inHandler := make(chan struct{})
+	release := make(chan struct{})
+
+	r := gin.New()
+	// An outer middleware setting per-caller headers, like a session.
+	r.Use(func(c *gin.Context) {
+		client := c.GetHeader("X-Client")
+		http.SetCookie(c.Writer, &http.Cookie{Name: "session", Value: client})
+		c.Header("X-RateLimit-Remaining", client)
+		c.Next()
+	})
+	r.Use(SingleFlight(nil))
+	r.GET("/x", func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			close(inHandler)
+			<-release
+		}
+		http.SetCookie(c.Writer, &http.Cookie{Name: "handler", Value: "1"})
+		c.Header("Cache-Control", "max-age=60")
+		c.String(http.StatusOK, "shared")
+	})
+
+	recorders := make([]*httptest.ResponseRecorder, callers)
+	var wg sync.WaitGroup
+	run := func(i int) {
+		defer wg.Done()
+		req := httptest.NewRequest(http.MethodGet, "/x", nil)
+		req.Header.Set("X-Client", "client"+strconv.Itoa(i))
+		recorders[i] = serve(r, req)
+	}
+	wg.Add(callers)
+	go run(0)
+	<-inHandler
+	for i := 1; i < callers; i++ {
+		go run(i)
+	}
+	// Give the waiters time to join the leader's flight.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler ran %d times, want 1", n)
+	}
+	for i, w := range recorders {
+		client := "client" + strconv.Itoa(i)
+		if w.Code != http.StatusOK || w.Body.String() != "shared" {
+			t.Errorf("%s: got %d %q", client, w.Code, w.Body.String())
+		}
+		var sessions []string
+		for _, c := range w.Result().Cookies() {
+			if c.Name == "session" {
+				sessions = append(sessions, c.Value)
+			}
+		}
+		if len(sessions) != 1 || sessions[0] != client {
+			t.Errorf("%s: session cookies = %v, want only its own", client, sessions)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != client {
+			t.Errorf("%s: X-RateLimit-Remaining = %q", client, got)
+		}
+		if got := w.Header().Values("X-RateLimit-Remaining"); len(got) != 1 {
+			t.Errorf("%s: X-RateLimit-Remaining = %v, want one value", client, got)
+		}
+		if got := w.Header().Get("Cache-Control"); got != "max-age=60" {
+			t.Errorf("%s: Cache-Control = %q, want the handler's", client, got)
+		}
+	}
+}
+
+func TestAddedHeaders(t *testing.T) {
+	before := http.Header{"Vary": {"Accept"}, "X-Outer": {"1"}}
+	after := http.Header{
+		"Vary":          {"Accept", "Accept-Language"},
+		"X-Outer":       {"1"},
+		"Content-Type":  {"text/plain"},
+		"Set-Cookie":    {"a=1"},
+		"Cache-Control": {"no-store"},
+	}
+	got := addedHeaders(before, after)
+	want := http.Header{
+		"Vary":          {"Accept-Language"},
+		"Content-Type":  {"text/plain"},
+		"Cache-Control": {"no-store"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("addedHeaders = %v, want %v", got, want)
+	}
+	for k, vs := range want {
+		if g := got[k]; len(g) != len(vs) || g[0] != vs[0] {
+			t.Errorf("%s = %v, want %v", k, g, vs)
+		}
+	}
+}
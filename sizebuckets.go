@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SizeBuckets sorts response sizes into a few buckets bounded by byte counts
+// and counts responses per bucket.
+type SizeBuckets struct {
+	bounds []int
+	labels []string
+
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// NewSizeBuckets uses bounds in bytes, or 1KB, 100KB and 1MB when none are
+// given. Bucket labels read "<1KB", ..., ">=1MB".
+func NewSizeBuckets(bounds ...int) *SizeBuckets {
+	if len(bounds) == 0 {
+		bounds = []int{1 << 10, 100 << 10, 1 << 20}
+	}
+	bounds = append([]int(nil), bounds...)
+	sort.Ints(bounds)
+	labels := make([]string, len(bounds)+1)
+	for i, b := range bounds {
+		labels[i] = "<" + formatSize(b)
+	}
+	labels[len(bounds)] = ">=" + formatSize(bounds[len(bounds)-1])
+	return &SizeBuckets{bounds: bounds, labels: labels, counts: map[string]int64{}}
+}
+
+// Snapshot returns the number of responses seen per bucket label.
+func (s *SizeBuckets) Snapshot() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]int64, len(s.counts))
+	for k, v := range s.counts {
+		out[k] = v
+	}
+	return out
+}
+
+// WithSizeBuckets makes Logger log the response's bucket in s as
+// response_size_bucket and count it.
+func WithSizeBuckets(s *SizeBuckets) Option {
+	return func(o *options) {
+		o.sizeBuckets = s
+	}
+}
+
+func (s *SizeBuckets) fields(c *gin.Context) []zap.Field {
+	size := c.Writer.Size()
+	if size < 0 {
+		size = 0
+	}
+	label := s.labels[sort.SearchInts(s.bounds, size+1)]
+	s.mu.Lock()
+	s.counts[label]++
+	s.mu.Unlock()
+	return []zap.Field{zap.String("response_size_bucket", label)}
+}
+
+func formatSize(n int) string {
+	switch {
+	case n >= 1<<30 && n%(1<<30) == 0:
+		return strconv.Itoa(n>>30) + "GB"
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return strconv.Itoa(n>>20) + "MB"
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return strconv.Itoa(n>>10) + "KB"
+	}
+	return strconv.Itoa(n) + "B"
+}
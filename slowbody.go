@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	slowBodiesMsg           = "slow_request_bodies"
+	defaultSlowBodyMaxBytes = 4 << 10
+)
+
+// WithSlowBodyCapture makes ResponseLogger keep up to maxBytes (4 KiB when not
+// positive) of the request and response bodies for every request, at any log
+// level, and log them at Warn only when the request took at least threshold.
+func WithSlowBodyCapture(threshold time.Duration, maxBytes int) Option {
+	if maxBytes <= 0 {
+		maxBytes = defaultSlowBodyMaxBytes
+	}
+	return func(o *options) {
+		o.slowBodyThreshold = threshold
+		o.slowBodyMaxBytes = maxBytes
+	}
+}
+
+// peekRequestBody returns up to n bytes of the request body and leaves the
+// full body readable for the handler.
+func peekRequestBody(c *gin.Context, n int) []byte {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil
+	}
+	prefix, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(n)))
+	if err != nil {
+		c.Request.Body = replayBody(prefix, err, c.Request.Body)
+		return prefix
+	}
+	c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(prefix), c.Request.Body), c.Request.Body}
+	return prefix
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func (o *options) logSlowBodies(logger *zap.Logger, c *gin.Context, latency time.Duration, reqBody, respBody []byte) {
+	if len(respBody) > o.slowBodyMaxBytes {
+		respBody = respBody[:o.slowBodyMaxBytes]
+	}
+	logger.Warn(slowBodiesMsg,
+		zap.String("xid", getRequestID(c)),
+		zap.String("method", c.Request.Method),
+		zap.String("path_uri", o.pathOf(c)),
+		zap.Int("status", c.Writer.Status()),
+		zap.String("latency", latency.String()),
+		zap.String("request_body", string(reqBody)),
+		zap.String("response_body", string(respBody)),
+	)
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestResponseLoggerSlowBodyCapture(t *testing.T) {
+	big := strings.Repeat("b", 2*defaultSlowBodyMaxBytes)
+	tests := []struct {
+		name     string
+		maxBytes int
+		delay    time.Duration
+		body     string
+		wantLog  bool
+		wantKept int
+	}{
+		{"fast", 8, 0, "0123456789", false, 0},
+		{"slow", 8, 20 * time.Millisecond, "0123456789", true, 8},
+		{"slow, short body", 64, 20 * time.Millisecond, "0123456789", true, 10},
+		{"zero max uses default", 0, 20 * time.Millisecond, big, true, defaultSlowBodyMaxBytes},
+		{"negative max uses default", -1, 20 * time.Millisecond, big, true, defaultSlowBodyMaxBytes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Info level: bodies are kept for the slow log alone.
+			logger, logs := observedLogger(zapcore.InfoLevel)
+			var handlerBody string
+			r := gin.New()
+			r.Use(ResponseLogger(logger, WithSlowBodyCapture(10*time.Millisecond, tt.maxBytes)))
+			r.POST("/x", func(c *gin.Context) {
+				b, _ := io.ReadAll(c.Request.Body)
+				handlerBody = string(b)
+				time.Sleep(tt.delay)
+				c.String(http.StatusOK, tt.body)
+			})
+
+			w := serve(r, httptest.NewRequest(http.MethodPost, "/x", strings.NewReader(tt.body)))
+
+			if handlerBody != tt.body {
+				t.Errorf("handler read %d bytes, want %d", len(handlerBody), len(tt.body))
+			}
+			if w.Body.String() != tt.body {
+				t.Errorf("client got %d bytes, want %d", w.Body.Len(), len(tt.body))
+			}
+			slow := logs.FilterMessage(slowBodiesMsg).All()
+			if got := len(slow) == 1; got != tt.wantLog {
+				t.Fatalf("logged %d slow bodies, want logged: %v", len(slow), tt.wantLog)
+			}
+			if !tt.wantLog {
+				return
+			}
+			fields := slow[0].ContextMap()
+			for _, k := range []string{"request_body", "response_body"} {
+				if got := fields[k].(string); got != tt.body[:tt.wantKept] {
+					t.Errorf("%s kept %d bytes, want %d", k, len(got), tt.wantKept)
+				}
+			}
+		})
+	}
+}
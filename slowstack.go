@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	slowStackMsg         = "slow_request_stack"
+	maxStackSnapshot     = 1 << 20
+	initialStackBytes    = 64 << 10
+	defaultStackInterval = 10 * time.Second
+)
+
+// WithSlowStack makes Logger dump all goroutine stacks when a request is still
+// running after threshold, at most once per minInterval across all requests.
+// A non-positive minInterval defaults to 10s; keep it generous, since each
+// dump can be up to 1 MiB.
+func WithSlowStack(threshold, minInterval time.Duration) Option {
+	if minInterval <= 0 {
+		minInterval = defaultStackInterval
+	}
+	s := &slowStack{threshold: threshold, minInterval: minInterval}
+	return func(o *options) {
+		o.slowStack = s
+	}
+}
+
+type slowStack struct {
+	threshold   time.Duration
+	minInterval time.Duration
+	last        atomic.Int64
+}
+
+// watch arms a timer for the request; the returned func disarms it.
+func (s *slowStack) watch(logger *zap.Logger, c *gin.Context, path string) func() {
+	xid, method, start := getRequestID(c), c.Request.Method, time.Now()
+	t := time.AfterFunc(s.threshold, func() {
+		now := time.Now().UnixNano()
+		last := s.last.Load()
+		if now-last < int64(s.minInterval) || !s.last.CompareAndSwap(last, now) {
+			return
+		}
+		logger.Warn(slowStackMsg,
+			zap.String("xid", xid),
+			zap.String("method", method),
+			zap.String("path_uri", path),
+			zap.String("elapsed", time.Since(start).String()),
+			zap.String("stack", string(allStacks())),
+		)
+	})
+	return func() { t.Stop() }
+}
+
+func allStacks() []byte {
+	buf := make([]byte, initialStackBytes)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxStackSnapshot {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWithSlowStackMinInterval(t *testing.T) {
+	tests := []struct {
+		in, want time.Duration
+	}{
+		{0, defaultStackInterval},
+		{-time.Second, defaultStackInterval},
+		{time.Second, time.Second},
+		{time.Minute, time.Minute},
+	}
+	for _, tt := range tests {
+		o := newOptions([]Option{WithSlowStack(time.Second, tt.in)})
+		if got := o.slowStack.minInterval; got != tt.want {
+			t.Errorf("WithSlowStack(_, %v) interval = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLoggerSlowStack(t *testing.T) {
+	logger, logs := observedLogger(zapcore.DebugLevel)
+	r := gin.New()
+	r.Use(Logger(logger, WithSlowStack(10*time.Millisecond, 0)))
+	r.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/slow", func(c *gin.Context) {
+		time.Sleep(40 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+
+	serve(r, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	time.Sleep(20 * time.Millisecond)
+	if n := logs.FilterMessage(slowStackMsg).Len(); n != 0 {
+		t.Fatalf("fast request dumped %d stacks", n)
+	}
+
+	// The second slow request falls inside the minimum interval.
+	serve(r, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	serve(r, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	dumps := logs.FilterMessage(slowStackMsg).All()
+	if len(dumps) != 1 {
+		t.Fatalf("got %d stack dumps, want 1", len(dumps))
+	}
+	fields := dumps[0].ContextMap()
+	if fields["path_uri"] != "/slow" {
+		t.Errorf("path_uri = %v, want /slow", fields["path_uri"])
+	}
+	if stack, _ := fields["stack"].(string); !strings.Contains(stack, "goroutine") {
+		t.Errorf("stack = %.80q", stack)
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const conflictingLengthMsg = "conflicting_length_headers"
+
+// RejectLengthConflict aborts with 400 requests that carry both a
+// Content-Length and a Transfer-Encoding, or several Content-Length values
+// that disagree, both request smuggling vectors (RFC 7230 3.3.3). With strip,
+// Content-Length is removed and Transfer-Encoding wins instead; disagreeing
+// lengths are always rejected. net/http already drops Content-Length from chunked requests it
+// parses itself, so this mainly guards requests that reach gin through other
+// servers or adapters.
+func RejectLengthConflict(logger *zap.Logger, strip bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		te := c.Request.TransferEncoding
+		if len(te) == 0 {
+			te = c.Request.Header.Values("Transfer-Encoding")
+		}
+		cls := c.Request.Header.Values("Content-Length")
+		mismatch := !sameLengths(cls)
+		if !mismatch && (len(te) == 0 || len(cls) == 0) {
+			c.Next()
+			return
+		}
+
+		stripped := strip && !mismatch
+		logger.Warn(conflictingLengthMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("method", c.Request.Method),
+			zap.String("path_uri", routePath(c)),
+			zap.String("content_length", strings.Join(cls, ", ")),
+			zap.String("transfer_encoding", strings.Join(te, ", ")),
+			zap.Bool("stripped", stripped),
+		)
+		if mismatch {
+			abortWithError(c, http.StatusBadRequest, "conflicting Content-Length values")
+			return
+		}
+		if !strip {
+			abortWithError(c, http.StatusBadRequest, "both Content-Length and Transfer-Encoding present")
+			return
+		}
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+		c.Next()
+	}
+}
+
+// sameLengths reports whether every Content-Length value, including the
+// elements of comma-separated lists, is the same.
+func sameLengths(values []string) bool {
+	var lengths []string
+	for _, v := range values {
+		for _, n := range strings.Split(v, ",") {
+			lengths = append(lengths, strings.TrimSpace(n))
+		}
+	}
+	for _, n := range lengths {
+		if n != lengths[0] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRejectLengthConflict(t *testing.T) {
+	tests := []struct {
+		name         string
+		strip        bool
+		cl           []string
+		te           []string // set on the request as net/http would
+		teHeader     []string // left in the header, as some adapters do
+		want         int
+		wantStripped bool
+	}{
+		{"content length only", false, []string{"4"}, nil, nil, http.StatusOK, false},
+		{"chunked only", false, nil, []string{"chunked"}, nil, http.StatusOK, false},
+		{"neither", false, nil, nil, nil, http.StatusOK, false},
+		{"repeated equal lengths", false, []string{"4", "4"}, nil, nil, http.StatusOK, false},
+		{"equal length list", false, []string{"4, 4"}, nil, nil, http.StatusOK, false},
+		{"cl and te", false, []string{"4"}, []string{"chunked"}, nil, http.StatusBadRequest, false},
+		{"cl and te header", false, []string{"4"}, nil, []string{"chunked"}, http.StatusBadRequest, false},
+		{"cl and other te", false, []string{"4"}, nil, []string{"gzip", "chunked"}, http.StatusBadRequest, false},
+		{"duplicate lengths", false, []string{"4", "40"}, nil, nil, http.StatusBadRequest, false},
+		{"duplicate length list", false, []string{"4, 40"}, nil, nil, http.StatusBadRequest, false},
+		{"empty duplicate length", false, []string{"", "4"}, nil, nil, http.StatusBadRequest, false},
+		{"strip cl and te", true, []string{"4"}, []string{"chunked"}, nil, http.StatusOK, true},
+		{"strip keeps duplicate lengths rejected", true, []string{"4", "40"}, nil, nil, http.StatusBadRequest, false},
+		{"strip keeps duplicate lengths with te rejected", true, []string{"4", "40"}, []string{"chunked"}, nil, http.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.WarnLevel)
+			var (
+				reached bool
+				gotCL   []string
+				gotLen  int64
+				gotBody string
+			)
+			r := gin.New()
+			r.Use(RejectLengthConflict(logger, tt.strip))
+			r.POST("/x", func(c *gin.Context) {
+				b, _ := io.ReadAll(c.Request.Body)
+				reached, gotBody = true, string(b)
+				gotCL, gotLen = c.Request.Header.Values("Content-Length"), c.Request.ContentLength
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/x", strings.NewReader("body"))
+			for _, v := range tt.cl {
+				req.Header.Add("Content-Length", v)
+			}
+			for _, v := range tt.teHeader {
+				req.Header.Add("Transfer-Encoding", v)
+			}
+			req.TransferEncoding = tt.te
+			w := serve(r, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if reached != (tt.want == http.StatusOK) {
+				t.Fatalf("handler reached = %v", reached)
+			}
+			if reached && gotBody != "body" {
+				t.Errorf("body = %q, want %q", gotBody, "body")
+			}
+			if tt.wantStripped && (gotCL != nil || gotLen != -1) {
+				t.Errorf("Content-Length = %q, ContentLength = %d, want stripped", gotCL, gotLen)
+			}
+
+			entries := logs.FilterMessage(conflictingLengthMsg).All()
+			if tt.want == http.StatusOK && !tt.wantStripped {
+				if len(entries) != 0 {
+					t.Errorf("logs = %v, want none", summaries(logs))
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("logs = %v, want one %s", summaries(logs), conflictingLengthMsg)
+			}
+			fields := entries[0].ContextMap()
+			if got, want := fields["content_length"], strings.Join(tt.cl, ", "); got != want {
+				t.Errorf("content_length = %v, want %q", got, want)
+			}
+			if fields["stripped"] != tt.wantStripped {
+				t.Errorf("stripped = %v, want %v", fields["stripped"], tt.wantStripped)
+			}
+		})
+	}
+}
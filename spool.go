@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const spoolErrorMsg = "body_spool_error"
+
+// WithBodySpool makes RequestLogger write request bodies over threshold bytes
+// to a temp file in dir (os.TempDir() when empty) instead of holding them in
+// memory. Handlers read the file; it is removed once the request is done.
+// Spooled bodies are hashed and their size logged, but never logged raw or
+// JSON-validated.
+func WithBodySpool(threshold int64, dir string) Option {
+	return func(o *options) {
+		o.spoolThreshold = threshold
+		o.spoolDir = dir
+	}
+}
+
+type spooledBody struct {
+	file *os.File
+	size int64
+	sum  []byte
+}
+
+func (s *spooledBody) remove() {
+	s.file.Close()
+	os.Remove(s.file.Name())
+}
+
+// readOrSpoolBody returns the body when it fits under the threshold, and
+// otherwise spools it to disk and returns the spool instead. Either way the
+// request body is left readable from the start, read errors included.
+func (o *options) readOrSpoolBody(logger *zap.Logger, c *gin.Context) ([]byte, *spooledBody) {
+	prefix, err := io.ReadAll(io.LimitReader(c.Request.Body, o.spoolThreshold+1))
+	rest := c.Request.Body
+	if err != nil || int64(len(prefix)) <= o.spoolThreshold {
+		c.Request.Body = replayBody(prefix, err, rest)
+		return prefix, nil
+	}
+
+	src := io.MultiReader(bytes.NewReader(prefix), rest)
+	f, err := os.CreateTemp(o.spoolDir, "request-body-*")
+	if err != nil {
+		return unspool(logger, c, nil, 0, nil, src, rest, err)
+	}
+	h := sha256.New()
+	buf := make([]byte, 32<<10)
+	var (
+		n       int64
+		readErr error
+	)
+	for {
+		nr, err := src.Read(buf)
+		if nr > 0 {
+			if _, werr := f.Write(buf[:nr]); werr != nil {
+				return unspool(logger, c, f, n, buf[:nr], src, rest, werr)
+			}
+			h.Write(buf[:nr])
+			n += int64(nr)
+		}
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
+			break
+		}
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return unspool(logger, c, f, n, nil, src, rest, err)
+	}
+	spooled := io.Reader(f)
+	if readErr != nil {
+		spooled = io.MultiReader(f, errReader{readErr})
+	}
+	c.Request.Body = readCloser{spooled, rest}
+	return nil, &spooledBody{file: f, size: n, sum: h.Sum(nil)}
+}
+
+// unspool falls back to memory, which beats failing the request, when the
+// spool file cannot be written: the body is what made it to f, then chunk,
+// then the rest of src. If f cannot be read back either, the handler gets the
+// bytes recovered from it followed by the error, never a silently short body.
+func unspool(logger *zap.Logger, c *gin.Context, f *os.File, n int64, chunk []byte, src io.Reader, rest io.Closer, err error) ([]byte, *spooledBody) {
+	logger.Warn(spoolErrorMsg, zap.String("xid", getRequestID(c)), zap.Error(err))
+	body := make([]byte, n, int(n)+len(chunk))
+	if f != nil {
+		m, err := f.ReadAt(body, 0)
+		f.Close()
+		os.Remove(f.Name())
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			c.Request.Body = replayBody(body[:m], fmt.Errorf("middleware: reading back spooled body: %w", err), rest)
+			return nil, nil
+		}
+	}
+	body = append(body, chunk...)
+	more, err := io.ReadAll(src)
+	body = append(body, more...)
+	c.Request.Body = replayBody(body, err, rest)
+	return body, nil
+}
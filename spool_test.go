@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRequestLoggerBodySpool(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantSpooled bool
+	}{
+		{"under threshold", "small", false},
+		{"over threshold", strings.Repeat("x", 100), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			logger, logs := observedLogger(zapcore.DebugLevel)
+			var read string
+			r := gin.New()
+			r.Use(RequestLogger(logger, WithBodySpool(10, dir)))
+			r.POST("/x", func(c *gin.Context) {
+				b, _ := io.ReadAll(c.Request.Body)
+				read = string(b)
+			})
+
+			serve(r, httptest.NewRequest(http.MethodPost, "/x", strings.NewReader(tt.body)))
+
+			if read != tt.body {
+				t.Errorf("handler read %d bytes, want %d", len(read), len(tt.body))
+			}
+			entries := logs.FilterMessage(requestInfoMsg).All()
+			if len(entries) != 1 {
+				t.Fatalf("got %d request logs, want 1", len(entries))
+			}
+			if spooled, _ := entries[0].ContextMap()["body_spooled"].(bool); spooled != tt.wantSpooled {
+				t.Errorf("body_spooled = %v, want %v", spooled, tt.wantSpooled)
+			}
+			if left, _ := os.ReadDir(dir); len(left) != 0 {
+				t.Errorf("spool dir still holds %d files", len(left))
+			}
+		})
+	}
+}
+
+func TestUnspool(t *testing.T) {
+	tests := []struct {
+		name     string
+		spooled  string // what made it to the spool file
+		n        int64  // what the spool was meant to hold
+		closed   bool   // the spool file cannot be read back
+		wantBody string
+		wantErr  bool
+	}{
+		{"read back", "abc", 3, false, "abcdefghi", false},
+		{"spool short", "abc", 6, false, "abc", true},
+		{"spool unreadable", "abc", 3, true, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.CreateTemp(t.TempDir(), "request-body-*")
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString(tt.spooled)
+			if tt.closed {
+				f.Close()
+			}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/x", nil)
+
+			unspool(zap.NewNop(), c, f, tt.n, []byte("def"), strings.NewReader("ghi"), io.NopCloser(nil), errors.New("disk full"))
+
+			got, err := io.ReadAll(c.Request.Body)
+			if string(got) != tt.wantBody {
+				t.Errorf("handler read %q, want %q", got, tt.wantBody)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handler read error = %v, want error: %v", err, tt.wantErr)
+			}
+			if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+				t.Error("spool file was not removed")
+			}
+		})
+	}
+}
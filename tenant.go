@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const missingTenantMsg = "missing_tenant"
+
+type tenantKey struct{}
+
+type tenant struct {
+	header string
+	id     string
+}
+
+// Tenant requires the tenant ID header (X-Tenant-ID when header is empty),
+// aborting with 400 without it. Attach it to the tenant-scoped routes. The ID
+// is put on the request context, logged by Logger as tenant_id and forwarded
+// by PropagateRequestID alongside the request ID.
+func Tenant(logger *zap.Logger, header string) gin.HandlerFunc {
+	if header == "" {
+		header = "X-Tenant-ID"
+	}
+	return func(c *gin.Context) {
+		id := c.GetHeader(header)
+		if id == "" {
+			logger.Warn(missingTenantMsg,
+				zap.String("xid", getRequestID(c)),
+				zap.String("method", c.Request.Method),
+				zap.String("path_uri", routePath(c)),
+				zap.String("header", header),
+			)
+			abortWithError(c, http.StatusBadRequest, "missing "+header+" header")
+			return
+		}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), tenantKey{}, tenant{header: header, id: id}))
+		c.Next()
+	}
+}
+
+// TenantIDFromContext accepts either the request context or the *gin.Context.
+func TenantIDFromContext(ctx context.Context) string {
+	ctx = requestContext(ctx)
+	t, _ := ctx.Value(tenantKey{}).(tenant)
+	return t.id
+}
+
+func tenantFields(c *gin.Context) []zap.Field {
+	if id := TenantIDFromContext(c); id != "" {
+		return []zap.Field{zap.String("tenant_id", id)}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const errorsSuppressedMsg = "error_logs_suppressed"
+
+type ThrottleOptions struct {
+	// Threshold is how many error summaries per Interval are always logged.
+	Threshold int
+	// Interval defaults to one second.
+	Interval time.Duration
+	// SampleEvery keeps one in SampleEvery errors past Threshold; defaults
+	// to 100.
+	SampleEvery int
+}
+
+// WithErrorThrottle samples error-level api summaries once they exceed
+// opts.Threshold per interval, keeping the first past it and then one in
+// SampleEvery. Suppressed lines are counted and the count logged as
+// error_logs_suppressed one interval after suppression starts, so the tail of
+// a storm is reported too.
+func WithErrorThrottle(opts ThrottleOptions) Option {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.SampleEvery <= 0 {
+		opts.SampleEvery = 100
+	}
+	t := &errorThrottle{opts: opts}
+	return func(o *options) {
+		o.errorThrottle = t
+	}
+}
+
+type errorThrottle struct {
+	opts ThrottleOptions
+
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+	suppressed  int
+	flushing    bool
+}
+
+func (t *errorThrottle) allow(logger *zap.Logger) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now := time.Now(); now.Sub(t.windowStart) >= t.opts.Interval {
+		t.windowStart = now
+		t.count = 0
+	}
+	t.count++
+	if t.sampled(t.count) {
+		return true
+	}
+	t.suppressed++
+	if !t.flushing {
+		t.flushing = true
+		time.AfterFunc(t.opts.Interval, func() { t.flush(logger) })
+	}
+	return false
+}
+
+// sampled reports whether the nth error of a window is logged.
+func (t *errorThrottle) sampled(n int) bool {
+	over := n - t.opts.Threshold
+	return over <= 0 || (over-1)%t.opts.SampleEvery == 0
+}
+
+func (t *errorThrottle) flush(logger *zap.Logger) {
+	t.mu.Lock()
+	n := t.suppressed
+	t.suppressed = 0
+	t.flushing = false
+	t.mu.Unlock()
+	if n > 0 {
+		logger.Warn(errorsSuppressedMsg, zap.Int("suppressed", n))
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestErrorThrottleSampled(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		every     int
+		want      []int // the errors of a window that are logged, out of the first 10
+	}{
+		{"zero threshold", 0, 3, []int{1, 4, 7, 10}},
+		{"threshold", 2, 3, []int{1, 2, 3, 6, 9}},
+		{"every one", 0, 1, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{"threshold above window", 20, 100, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			th := &errorThrottle{opts: ThrottleOptions{Threshold: tt.threshold, SampleEvery: tt.every}}
+			var got []int
+			for n := 1; n <= 10; n++ {
+				if th.sampled(n) {
+					got = append(got, n)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("logged %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("logged %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggerErrorThrottle(t *testing.T) {
+	logger, logs := observedLogger(zapcore.DebugLevel)
+	r := gin.New()
+	r.Use(Logger(logger, WithOutcomeClassifier(StatusOutcome), WithErrorThrottle(ThrottleOptions{
+		Threshold:   1,
+		Interval:    50 * time.Millisecond,
+		SampleEvery: 3,
+	})))
+	r.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+	r.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for i := 0; i < 6; i++ {
+		serve(r, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	}
+	serve(r, httptest.NewRequest(http.MethodGet, "/ok", nil))
+
+	// Errors 1, 2 and 5 are logged, 3, 4 and 6 suppressed; successes are
+	// never throttled.
+	if got := len(summaries(logs)); got != 4 {
+		t.Fatalf("got %d api summaries, want 4", got)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for logs.FilterMessage(errorsSuppressedMsg).Len() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("suppressed count was never flushed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	flushed := logs.FilterMessage(errorsSuppressedMsg).All()
+	if len(flushed) != 1 {
+		t.Fatalf("flushed %d times, want 1", len(flushed))
+	}
+	if n := flushed[0].ContextMap()["suppressed"]; n != int64(3) {
+		t.Errorf("suppressed = %v, want 3", n)
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const tlsRejectedMsg = "tls_version_rejected"
+
+type TLSOptions struct {
+	// MinVersion defaults to tls.VersionTLS12.
+	MinVersion uint16
+	// Status defaults to 400.
+	Status int
+	// VersionHeader is read when the app does not terminate TLS itself, for a
+	// proxy that forwards the negotiated version ("TLSv1.2" or "1.2").
+	VersionHeader string
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// MinTLSVersion rejects connections negotiated below opts.MinVersion. Without
+// TLS state or a version header it does nothing.
+func MinTLSVersion(logger *zap.Logger, opts TLSOptions) gin.HandlerFunc {
+	if opts.MinVersion == 0 {
+		opts.MinVersion = tls.VersionTLS12
+	}
+	if opts.Status == 0 {
+		opts.Status = http.StatusBadRequest
+	}
+	return func(c *gin.Context) {
+		var version uint16
+		switch {
+		case c.Request.TLS != nil:
+			version = c.Request.TLS.Version
+		case opts.VersionHeader != "":
+			v := strings.TrimPrefix(strings.ToUpper(c.GetHeader(opts.VersionHeader)), "TLSV")
+			version = tlsVersions[v]
+		}
+		if version == 0 || version >= opts.MinVersion {
+			c.Next()
+			return
+		}
+
+		logger.Warn(tlsRejectedMsg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("path_uri", routePath(c)),
+			zap.String("tls_version", tlsVersionName(version)),
+		)
+		abortWithError(c, opts.Status, "TLS version not supported")
+	}
+}
+
+func tlsVersionName(version uint16) string {
+	for name, v := range tlsVersions {
+		if v == version {
+			return "TLS " + name
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TraceEvent is a complete ("X") event in the Chrome trace event format.
+// Timestamps and durations are in microseconds.
+type TraceEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat"`
+	Ph   string                 `json:"ph"`
+	Ts   int64                  `json:"ts"`
+	Dur  int64                  `json:"dur"`
+	Pid  int                    `json:"pid"`
+	Tid  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args"`
+}
+
+// WithTraceEvents makes Logger hand a TraceEvent per request to sink.
+func WithTraceEvents(sink func(TraceEvent)) Option {
+	return func(o *options) {
+		o.traceSink = sink
+	}
+}
+
+// TraceEventWriter returns a sink writing events to w as a JSON array without
+// the closing bracket, which trace viewers accept as is.
+func TraceEventWriter(w io.Writer) func(TraceEvent) {
+	var mu sync.Mutex
+	started := false
+	return func(e TraceEvent) {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if !started {
+			w.Write([]byte("[\n"))
+			started = true
+		}
+		w.Write(append(b, ",\n"...))
+	}
+}
+
+var (
+	pid = os.Getpid()
+	// traceTid gives every request its own lane, so concurrent requests
+	// never overlap on one and break the viewer's nesting.
+	traceTid atomic.Int64
+)
+
+func traceEvent(c *gin.Context, name string, start time.Time, status int) TraceEvent {
+	return TraceEvent{
+		Name: c.Request.Method + " " + name,
+		Cat:  "http",
+		Ph:   "X",
+		Ts:   start.UnixMicro(),
+		Dur:  time.Since(start).Microseconds(),
+		Pid:  pid,
+		Tid:  int(traceTid.Add(1)),
+		Args: map[string]interface{}{
+			"xid":    getRequestID(c),
+			"status": status,
+		},
+	}
+}
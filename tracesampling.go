@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTraceSampling skips request and response body logging when the request
+// context carries a span that was not sampled, keeping logs proportional to
+// traces. The api summary is always written; requests without trace context
+// are unaffected.
+func WithTraceSampling() Option {
+	return func(o *options) {
+		o.traceSampling = true
+	}
+}
+
+func traceUnsampled(c *gin.Context) bool {
+	sc := trace.SpanContextFromContext(c.Request.Context())
+	return sc.IsValid() && !sc.IsSampled()
+}
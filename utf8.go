@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	invalidUTF8Msg  = "invalid_utf8"
+	replacementChar = "\uFFFD"
+)
+
+type UTF8Mode int
+
+const (
+	// RejectInvalidUTF8 aborts with 400.
+	RejectInvalidUTF8 UTF8Mode = iota
+	// SanitizeInvalidUTF8 replaces invalid sequences with U+FFFD. Routing has
+	// already happened, so only handlers and later middleware see the result.
+	SanitizeInvalidUTF8
+)
+
+// ValidUTF8 guards against invalid or overlong UTF-8 in the decoded path and
+// in each decoded query key and value. Malformed escapes such as "100%" are
+// not its business and pass through untouched, as gin leaves them; sanitizing
+// rewrites only the invalid keys and values and keeps every pair in order.
+func ValidUTF8(logger *zap.Logger, mode UTF8Mode) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		u := c.Request.URL
+		if utf8.ValidString(u.Path) && validQueryUTF8(u.RawQuery) {
+			c.Next()
+			return
+		}
+
+		logger.Warn(invalidUTF8Msg,
+			zap.String("xid", getRequestID(c)),
+			zap.String("method", c.Request.Method),
+			zap.String("path_uri", strings.ToValidUTF8(routePath(c), replacementChar)),
+			zap.Bool("sanitized", mode == SanitizeInvalidUTF8),
+		)
+		if mode != SanitizeInvalidUTF8 {
+			abortWithError(c, http.StatusBadRequest, "invalid UTF-8 in request URL")
+			return
+		}
+
+		u.Path = strings.ToValidUTF8(u.Path, replacementChar)
+		u.RawPath = ""
+		u.RawQuery = sanitizeQueryUTF8(u.RawQuery)
+		c.Next()
+	}
+}
+
+func validQueryUTF8(raw string) bool {
+	for _, pair := range strings.Split(raw, "&") {
+		k, v, _ := strings.Cut(pair, "=")
+		if !utf8.ValidString(lenientUnescape(k)) || !utf8.ValidString(lenientUnescape(v)) {
+			return false
+		}
+	}
+	return true
+}
+
+// sanitizeQueryUTF8 re-escapes the keys and values of raw that decode to
+// invalid UTF-8, with the invalid sequences replaced, and leaves the rest of
+// raw byte for byte.
+func sanitizeQueryUTF8(raw string) string {
+	clean := func(part string) string {
+		if s := lenientUnescape(part); !utf8.ValidString(s) {
+			return url.QueryEscape(strings.ToValidUTF8(s, replacementChar))
+		}
+		return part
+	}
+	pairs := strings.Split(raw, "&")
+	for i, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if ok {
+			pairs[i] = clean(k) + "=" + clean(v)
+		} else {
+			pairs[i] = clean(k)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// lenientUnescape decodes a query component like url.QueryUnescape but keeps
+// malformed escapes as they are instead of failing.
+func lenientUnescape(s string) string {
+	if !strings.ContainsAny(s, "%+") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '+':
+			b.WriteByte(' ')
+		case s[i] == '%' && i+2 < len(s) && ishex(s[i+1]) && ishex(s[i+2]):
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func ishex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
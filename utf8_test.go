@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestValidUTF8(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		invalid bool
+	}{
+		{"ascii", "/p/hello?q=world", false},
+		{"valid multibyte", "/p/caf%C3%A9?q=%E2%82%AC", false},
+		{"lone continuation byte", "/p/%80", true},
+		{"invalid lead byte", "/p/%FF", true},
+		{"overlong slash", "/p/%C0%AF", true},
+		{"overlong NUL", "/p/%E0%80%80", true},
+		{"UTF-16 surrogate", "/p/%ED%A0%80", true},
+		{"truncated sequence", "/p/%E2%82", true},
+		{"beyond U+10FFFF", "/p/%F4%90%80%80", true},
+		{"invalid in query value", "/p/ok?q=%C0%AF", true},
+		{"invalid in query key", "/p/ok?%FF=1", true},
+		{"malformed escape", "/p/ok?q=100%", false},
+		{"malformed escape in key", "/p/ok?%zz=1", false},
+		{"truncated escape", "/p/ok?q=%E", false},
+		{"plus as space", "/p/ok?q=a+b", false},
+	}
+	for _, tt := range tests {
+		for _, mode := range []UTF8Mode{RejectInvalidUTF8, SanitizeInvalidUTF8} {
+			t.Run(fmt.Sprintf("%s/mode=%d", tt.name, mode), func(t *testing.T) {
+				var gotPath, gotQuery string
+				r := gin.New()
+				r.Use(ValidUTF8(zap.NewNop(), mode))
+				r.GET("/p/*rest", func(c *gin.Context) {
+					gotPath, gotQuery = c.Request.URL.Path, c.Request.URL.RawQuery
+					c.Status(http.StatusOK)
+				})
+
+				w := serve(r, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+				want := http.StatusOK
+				if tt.invalid && mode == RejectInvalidUTF8 {
+					want = http.StatusBadRequest
+				}
+				if w.Code != want {
+					t.Fatalf("status = %d, want %d", w.Code, want)
+				}
+				if w.Code == http.StatusOK && !utf8.ValidString(gotPath) {
+					t.Errorf("handler saw invalid path %q", gotPath)
+				}
+				if w.Code == http.StatusOK && tt.invalid && !validQuery(gotQuery) {
+					t.Errorf("handler saw invalid query %q", gotQuery)
+				}
+			})
+		}
+	}
+}
+
+func TestValidUTF8SanitizeKeepsPairs(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"a=%ff&b=50%&c=1", "a=%EF%BF%BD&b=50%&c=1"},
+		{"%ff=1&b", "%EF%BF%BD=1&b"},
+		{"a=1&a=%C0%AF&a=3", "a=1&a=%EF%BF%BD&a=3"},
+		{"q=caf%C3%A9&x=%80+y", "q=caf%C3%A9&x=%EF%BF%BD+y"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got string
+			r := gin.New()
+			r.Use(ValidUTF8(zap.NewNop(), SanitizeInvalidUTF8))
+			r.GET("/p", func(c *gin.Context) { got = c.Request.URL.RawQuery })
+
+			serve(r, httptest.NewRequest(http.MethodGet, "/p?"+tt.query, nil))
+
+			if got != tt.want {
+				t.Errorf("query = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func validQuery(raw string) bool {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return false
+	}
+	for k, vs := range values {
+		if !utf8.ValidString(k) {
+			return false
+		}
+		for _, v := range vs {
+			if !utf8.ValidString(v) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// Version sets header (X-Server-Version when empty) to version on every
+// response, before the handlers run so it is in place whenever they flush.
+// Pair it with WithServerVersion to log the same value.
+func Version(version, header string) gin.HandlerFunc {
+	if header == "" {
+		header = "X-Server-Version"
+	}
+	return func(c *gin.Context) {
+		c.Header(header, version)
+		c.Next()
+	}
+}
+
+// WithServerVersion adds a static server_version field to the api summary.
+func WithServerVersion(version string) Option {
+	return func(o *options) {
+		o.serverVersion = version
+	}
+}
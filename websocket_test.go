@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWebSocketOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		upgrade bool
+		origin  string
+		want    int
+	}{
+		{"plain request", false, "https://evil.test", http.StatusOK},
+		{"exact origin", true, "https://app.example.com", http.StatusOK},
+		{"case folded", true, "HTTPS://App.Example.com", http.StatusOK},
+		{"wildcard subdomain", true, "https://a.cdn.test", http.StatusOK},
+		{"cross origin", true, "https://evil.test", http.StatusForbidden},
+		{"missing origin", true, "", http.StatusForbidden},
+		{"null origin", true, "null", http.StatusForbidden},
+		{"other scheme", true, "http://app.example.com", http.StatusForbidden},
+		{"other port", true, "https://app.example.com:8443", http.StatusForbidden},
+		{"wildcard apex", true, "https://cdn.test", http.StatusForbidden},
+		{"suffix lookalike", true, "https://evilcdn.test", http.StatusForbidden},
+		{"allowed origin as prefix", true, "https://app.example.com.evil.test", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(zapcore.WarnLevel)
+			reached := false
+			r := gin.New()
+			r.Use(WebSocketOrigin(logger, "https://app.example.com", "https://*.cdn.test"))
+			r.GET("/ws", func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			if tt.upgrade {
+				req.Header.Set("Connection", "keep-alive, Upgrade")
+				req.Header.Set("Upgrade", "websocket")
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := serve(r, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if reached != (tt.want == http.StatusOK) {
+				t.Errorf("handler reached = %v", reached)
+			}
+			entries := logs.FilterMessage(originRejectedMsg).All()
+			if tt.want == http.StatusOK {
+				if len(entries) != 0 {
+					t.Errorf("logs = %v, want none", summaries(logs))
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("logs = %v, want one %s", summaries(logs), originRejectedMsg)
+			}
+			if got := entries[0].ContextMap()["origin"]; got != tt.origin {
+				t.Errorf("origin = %v, want %q", got, tt.origin)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package middleware
+
+// WithWriteAfterCancel makes ResponseLogger warn with wrote_after_cancel=true
+// when a handler keeps writing after the request context is done, e.g. the
+// client went away, which usually means wasted work or a leaked goroutine.
+func WithWriteAfterCancel() Option {
+	return func(o *options) {
+		o.writeAfterCancel = true
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestResponseLoggerWriteAfterCancel(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     zapcore.Level
+		cancelled bool
+		wantLate  int64
+		wantBody  bool
+	}{
+		{"in time", zapcore.InfoLevel, false, 0, false},
+		{"late", zapcore.InfoLevel, true, 2, false},
+		{"late, bodies logged", zapcore.DebugLevel, true, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, logs := observedLogger(tt.level)
+			r := gin.New()
+			r.Use(ResponseLogger(logger, WithWriteAfterCancel()))
+			r.GET("/x", func(c *gin.Context) {
+				c.Writer.Write([]byte("a"))
+				c.Writer.Write([]byte("b"))
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.cancelled {
+				cancel()
+			}
+			defer cancel()
+			serve(r, httptest.NewRequest(http.MethodGet, "/x", nil).WithContext(ctx))
+
+			var late int64
+			body := false
+			for _, e := range logs.FilterMessage(responseInfoMsg).All() {
+				fields := e.ContextMap()
+				if n, ok := fields["late_writes"].(int64); ok {
+					late = n
+				}
+				if _, ok := fields["body"]; ok {
+					body = true
+				}
+			}
+			if late != tt.wantLate {
+				t.Errorf("late_writes = %d, want %d", late, tt.wantLate)
+			}
+			if body != tt.wantBody {
+				t.Errorf("body logged = %v, want %v", body, tt.wantBody)
+			}
+		})
+	}
+}